@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// DefaultTimeout is how long a bot has to answer, unless it's given another
+const DefaultTimeout = 5 * time.Second
+
+/*
+An Agent is a running bot.  It's a game.Chooser, so it can be given to a seat, and it plays every game
+that seat plays until it's closed.
+*/
+type Agent struct {
+	Name    string
+	Timeout time.Duration
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	lines   chan string        // what the bot writes, closed when it stops
+	broken  error              // why the bot can't play any more, nil while it can
+	seat    *player.PlayerView // the seat it's playing now, which tells one game from the next
+	lost    *player.PlayerView // a seat it forfeited, whose decisions it makes no more
+	asked   int                // counts the decisions asked, which numbers them
+}
+
+// Start runs the bot command, split on spaces like "python3 bots/randombot.py", and waits for it to say hello
+func Start(command string, timeout time.Duration) (*Agent, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no bot command")
+	}
+	a := &Agent{Name: command, Timeout: timeout, cmd: exec.Command(args[0], args[1:]...), lines: make(chan string, 1)}
+	a.cmd.Stderr = os.Stderr
+	var err error
+	if a.in, err = a.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	out, err := a.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = a.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("bot %s: %v", command, err)
+	}
+	go func() {
+		scanner := bufio.NewScanner(out)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			a.lines <- scanner.Text()
+		}
+		close(a.lines)
+	}()
+
+	reply, err := a.exchange(Message{Type: "hello", Protocol: Protocol})
+	if err != nil {
+		a.Close()
+		return nil, fmt.Errorf("bot %s: %v", command, err)
+	}
+	if reply.Name != "" {
+		a.Name = reply.Name
+	}
+	return a, nil
+}
+
+func (a *Agent) send(m Message) error {
+	if a.broken != nil {
+		return a.broken
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err = a.in.Write(append(data, '\n')); err != nil {
+		a.stop(fmt.Errorf("the bot stopped listening"))
+	}
+	return a.broken
+}
+
+/*
+exchange sends a message and waits for the answer.  A bot that doesn't answer in time is stopped.  For a
+decision, an answer with another decision's id is dropped, and the bot still has the rest of its time to give
+the right one.
+*/
+func (a *Agent) exchange(m Message) (reply Reply, err error) {
+	if err = a.send(m); err != nil {
+		return
+	}
+	timeout := time.After(a.Timeout)
+	for {
+		select {
+		case line, ok := <-a.lines:
+			if !ok {
+				a.stop(fmt.Errorf("the bot quit"))
+				return reply, a.broken
+			}
+			reply = Reply{}
+			if err = json.Unmarshal([]byte(line), &reply); err != nil {
+				return reply, fmt.Errorf("can't read %q: %v", line, err)
+			}
+			if m.Type == "decide" && reply.ID != m.ID {
+				continue
+			}
+			return
+		case <-timeout:
+			a.stop(fmt.Errorf("no answer in %v", a.Timeout))
+			return reply, a.broken
+		}
+	}
+}
+
+// stop kills the bot, which can't be trusted to play any more
+func (a *Agent) stop(why error) {
+	if a.broken == nil {
+		a.broken = why
+	}
+	a.cmd.Process.Kill()
+}
+
+// Choose asks the bot.  A bot that breaks the rules forfeits the game, and the first option is taken for
+// it until the turn is out.
+func (a *Agent) Choose(view *player.PlayerView, d game.Decision) int {
+	if view == a.lost {
+		return 0
+	}
+	if view != a.seat {
+		a.seat = view
+		a.send(Message{Type: "start", Seat: d.Seat, Timeout: a.Timeout.Milliseconds(), Rules: d.Rules})
+	}
+	a.asked++
+	ask := Ask(view, d)
+	ask.ID = a.asked
+	reply, err := a.exchange(ask)
+	if err == nil && reply.Choice == nil {
+		err = fmt.Errorf("no choice in the answer")
+	} else if err == nil && (*reply.Choice < 0 || *reply.Choice >= len(d.Options)) {
+		err = fmt.Errorf("chose %d of %d options", *reply.Choice, len(d.Options))
+	}
+	if err != nil {
+		d.Forfeit(fmt.Sprintf("%s: %s: %v", a.Name, game.DecisionName(d.Type), err))
+		a.lost = view
+		return 0
+	}
+	return *reply.Choice
+}
+
+// Finish tells the bot how the game came out
+func (a *Agent) Finish(view *player.PlayerView, vp []int, winner int) {
+	a.send(Message{Type: "end", Seat: view.Seat, VP: vp, Winner: &winner})
+	a.seat, a.lost = nil, nil
+}
+
+// Close ends the bot's input, which is its cue to quit, and stops it if it hasn't by the timeout
+func (a *Agent) Close() error {
+	a.in.Close()
+	done := make(chan error, 1)
+	go func() { done <- a.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(a.Timeout):
+		a.cmd.Process.Kill()
+		return <-done
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Package bot lets a program outside the engine play a seat, so bots can be written in any language.  The
+engine starts the program and talks to it over its stdin and stdout, one JSON object to a line, a little
+like UCI for chess.  Whatever the bot writes to stderr shows up on the engine's stderr, for debugging.
+
+When the bot starts the engine says hello, and the bot answers with its name:
+
+	{"type":"hello","protocol":2}
+	{"name":"random"}
+
+At the start of each game the bot plays, it's told its seat, the rules and how long it has to answer, in
+milliseconds:
+
+	{"type":"start","seat":1,"timeout":5000,"rules":{...}}
+
+The rules are game.Rules, with its field names as they are, so they read the same as in a saved game:
+
+	{"StorageFill":0,"SpendStorage":true,"HandLimitTo":0,"SoldiersTo":0,"RedrawOnFullHand":true,
+	 "TurnCap":30,"SoldiersDefend":false,"RedrawToTrash":false}
+
+StorageFill is 0 when every storage build fills any open spot it covers, or 1 when only Shed and Storehouse
+fill their own new spot, as the card text says.  SpendStorage is whether cards in storage can pay for a build.
+HandLimitTo and SoldiersTo say where cards discarded down to the hand limit and soldiers that have attacked
+go: 0 for the trash, 1 for the discard pile.  RedrawOnFullHand makes a player who can't build with a full
+hand dump it and redraw, and RedrawToTrash puts the dumped hand in the trash rather than out of the game.
+TurnCap ends the game after that many turns, 0 for no cap.  SoldiersDefend lets a player throw a soldier in
+the way of an attack, which is the defend decision.
+
+Then for each decision its seat has to make, it gets what the seat can see of the game and the legal
+options, and answers with the index of the one it takes.  Each decision has an id, which goes back with the
+answer; an answer with any other id isn't for this decision, and is dropped:
+
+	{"type":"decide","id":7,"decision":"build","state":{...},"options":["pass","build Mine","build stored Farm"]}
+	{"id":7,"choice":1}
+
+The decisions are build, discards, redraw, attack, defend, store, trash, draw and hand limit, as in
+game.DecisionName.  Where a decision is about a card, "card" names it: the card being built for discards,
+the attacking soldier for defend, the top of the discard for draw.  For defend, "target" is the kind of
+building under attack.  The state is a State, below.
+
+When the game is over the bot is told how it went, with -1 for a tie:
+
+	{"type":"end","seat":1,"vp":[12,9],"winner":0}
+
+Only hello and decide need an answer.  A bot that answers late, answers with anything but one of the
+options, or quits, forfeits the game.  One that was late or quit is stopped, and forfeits the rest of its
+games too.  See bots/randombot.py for a bot that does the least it can.
+*/
+package bot
+
+import (
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// Protocol is the version of the protocol, sent with hello.  2 added the decision ids.
+const Protocol = 2
+
+// Message is anything the engine sends.  Only the fields that go with the type are set.
+type Message struct {
+	Type     string      `json:"type"`
+	ID       int         `json:"id,omitempty"` // the decision being asked, to give with the answer
+	Protocol int         `json:"protocol,omitempty"`
+	Seat     int         `json:"seat"`
+	Timeout  int64       `json:"timeout,omitempty"`
+	Rules    *game.Rules `json:"rules,omitempty"`
+	Decision string      `json:"decision,omitempty"`
+	Card     string      `json:"card,omitempty"`
+	Target   string      `json:"target,omitempty"`
+	State    *State      `json:"state,omitempty"`
+	Options  []string    `json:"options,omitempty"`
+	VP       []int       `json:"vp,omitempty"`
+	Winner   *int        `json:"winner,omitempty"`
+}
+
+// Reply is anything the bot sends back
+type Reply struct {
+	Name   string `json:"name"`
+	ID     int    `json:"id"` // the decision the choice is for
+	Choice *int   `json:"choice"`
+}
+
+/*
+State is the game as one seat sees it: its own hand, everything on the table, and the face up piles.  The
+stock and the opponent's hand are only counted, apart from the cards the seat saw go into the opponent's
+hand.  Cards are given by name, and piles bottom first, so the last card of Discard is the one on top.
+*/
+type State struct {
+	Turn          int      `json:"turn"`
+	Seat          int      `json:"seat"`
+	Hand          []string `json:"hand"`
+	Seats         [2]Side  `json:"seats"`
+	Stock         int      `json:"stock"`
+	Discard       []string `json:"discard"`
+	Trash         []string `json:"trash"`
+	OpponentHolds []string `json:"opponent_holds"`
+}
+
+// Side is what's on the table in front of a seat
+type Side struct {
+	Tableau map[string][]string `json:"tableau"` // by kind name, each stack bottom first
+	Storage [2]string           `json:"storage"` // "" for an empty spot
+	Hand    int                 `json:"hand"`    // how many cards are in the hand
+	VP      int                 `json:"vp"`
+}
+
+func names(cards []*card.Card) (list []string) {
+	list = []string{}
+	for _, c := range cards {
+		if c != nil {
+			list = append(list, c.Name)
+		}
+	}
+	return
+}
+
+func side(tableau *card.Tableau, hand int) Side {
+	side := Side{Tableau: make(map[string][]string), Hand: hand}
+	for kind, stack := range tableau.Stack {
+		if stack != nil {
+			side.Tableau[card.KindName(kind)] = names(stack.Cards)
+			side.VP += tableau.Top(kind).VictoryPoints
+		}
+	}
+	for spot, c := range tableau.Storage {
+		if c != nil {
+			side.Storage[spot] = c.Name
+		}
+	}
+	return side
+}
+
+// See puts a seat's view into a State
+func See(view *player.PlayerView) *State {
+	s := &State{
+		Turn:          view.Clock.Turn,
+		Seat:          view.Seat,
+		Hand:          names(view.Hand.Cards),
+		Stock:         view.Stock(),
+		Discard:       names(view.DiscardPile.Cards[:view.DiscardPile.PullPos+1]),
+		Trash:         names(view.Trash.Cards[:view.Trash.PullPos+1]),
+		OpponentHolds: names(view.OpponentHolding()),
+	}
+	s.Seats[view.Seat] = side(view.Tableau, len(s.Hand))
+	s.Seats[1-view.Seat] = side(view.Opponent, view.OpponentHand())
+	return s
+}
+
+// Ask puts a decision into a message, with what the seat can see
+func Ask(view *player.PlayerView, d game.Decision) Message {
+	m := Message{Type: "decide", Seat: d.Seat, Decision: game.DecisionName(d.Type), State: See(view)}
+	if d.Card != nil {
+		m.Card = d.Card.Name
+	}
+	if d.Type == game.DecideDefend {
+		m.Target = card.KindName(d.Kind)
+	}
+	for _, option := range d.Options {
+		m.Options = append(m.Options, option.Label)
+	}
+	return m
+}
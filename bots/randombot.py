@@ -0,0 +1,18 @@
+#!/usr/bin/env python3
+"""A bot that takes a random option at every decision.  See package bot for the protocol.
+
+    warwick play -seat1 "bot:python3 bots/randombot.py"
+"""
+import json
+import random
+import sys
+
+for line in sys.stdin:
+    message = json.loads(line)
+    if message["type"] == "hello":
+        reply = {"name": "random"}
+    elif message["type"] == "decide":
+        reply = {"id": message["id"], "choice": random.randrange(len(message["options"]))}
+    else:
+        continue
+    print(json.dumps(reply), flush=True)
@@ -2,6 +2,7 @@ package card
 
 import (
 	"fmt"
+	"strings"
 )
 
 const Farm = 0
@@ -59,11 +60,17 @@ func (c Card)String() string{
 }
 
 
+// put a card face up on top of a pile, like the discard or the trash
+func (pile *Hand) Place(c *Card) {
+	(*pile).Cards[(*pile).PullPos + 1] = c
+	(*pile).PullPos += 1
+}
+
+
 func (hand *Hand) RemoveCard(pos int, pile *Hand) {
 	// move the card somewhere else if given
 	if pile != nil {
-		(*pile).Cards[(*pile).PullPos + 1] = (*hand).Cards[pos]
-		(*pile).PullPos += 1
+		pile.Place((*hand).Cards[pos])
 	}
 	// remove the card from the hand
 	(*hand).Cards[pos] = nil
@@ -74,8 +81,7 @@ func (hand *Hand) RemoveCard(pos int, pile *Hand) {
 func (tableau *Tableau) RemoveFromStorage(pos int, pile *Hand) {
 	// move the card somewhere else if given
 	if pile != nil {
-		(*pile).Cards[(*pile).PullPos + 1] = (*tableau).Storage[pos]
-		(*pile).PullPos += 1
+		pile.Place((*tableau).Storage[pos])
 	}
 	// remove the card from the hand
 	(*tableau).Storage[pos] = nil
@@ -108,10 +114,12 @@ func (h Hand)String() string{
 }
 
 
-// dump the hand
-// TODO: move to the trash
-func (from *Hand) Reset() {
+// dump the hand, moving the cards onto the given pile if there is one
+func (from *Hand) Reset(pile *Hand) {
 	for	pos := 0; pos < (*from).Max; pos++ { 
+		if pile != nil && (*from).Cards[pos] != nil {
+			pile.Place((*from).Cards[pos])
+		}
 		(*from).Cards[pos] = nil
 	}
 	(*from).Count = 0
@@ -189,6 +197,45 @@ func (t Tableau)String() string{
 }
 
 
+// the card in power on a stack, or nil if nothing of that kind is built
+func (t Tableau) Top(kind int) *Card {
+	if t.Stack[kind] == nil {
+		return nil
+	}
+	return t.Stack[kind].Cards[t.Stack[kind].PullPos]
+}
+
+
+// Recount works out the discounts, bonuses and fill from the cards in power, for a tableau that was set
+// up by hand rather than built up card by card
+func (t *Tableau) Recount() {
+	if len(t.Discounts) != 4 {
+		t.Discounts = make([]int, 4)
+	}
+	for i := range t.Discounts {
+		t.Discounts[i] = 0
+	}
+	t.Fill, t.BuildBonus, t.DrawFromDiscardPower, t.TrashBonus, t.DrawBonus, t.AttackBonus = 0, 0, 0, 0, 0, 0
+	for kind := 0; kind <= 9; kind++ {
+		top := t.Top(kind)
+		if top == nil {
+			continue
+		}
+		for i := range t.Discounts {
+			t.Discounts[i] += top.CostModifier[i]
+		}
+		t.BuildBonus += top.BuildBonus
+		t.DrawFromDiscardPower += top.DrawFromDiscardPower
+		t.TrashBonus += top.TrashBonus
+		t.DrawBonus += top.DrawBonus
+		t.AttackBonus += top.AttackBonus
+		if kind != Soldiers {
+			t.Fill++
+		}
+	}
+}
+
+
 // remove the top card from that tableau stack, adding to the given hand
 func (tableau *Tableau) RemoveTop(kind int, hand *Hand) {
 	top := (*tableau).Stack[kind].PullPos
@@ -209,6 +256,9 @@ func (tableau *Tableau) RemoveTop(kind int, hand *Hand) {
 	(*tableau).TrashBonus -= (*tableau).Stack[kind].Cards[top].TrashBonus
 	(*tableau).DrawFromDiscardPower -= (*tableau).Stack[kind].Cards[top].DrawFromDiscardPower
 	(*tableau).AttackBonus -= (*tableau).Stack[kind].Cards[top].AttackBonus
+	for i := range (*tableau).Discounts {
+		(*tableau).Discounts[i] -= (*tableau).Stack[kind].Cards[top].CostModifier[i]
+	}
 	(*tableau).Stack[kind].Cards[top] = nil
 	top--
 
@@ -227,6 +277,9 @@ func (tableau *Tableau) RemoveTop(kind int, hand *Hand) {
 		(*tableau).TrashBonus += (*tableau).Stack[kind].Cards[top].TrashBonus
 		(*tableau).DrawFromDiscardPower += (*tableau).Stack[kind].Cards[top].DrawFromDiscardPower
 		(*tableau).AttackBonus += (*tableau).Stack[kind].Cards[top].AttackBonus
+		for i := range (*tableau).Discounts {
+			(*tableau).Discounts[i] += (*tableau).Stack[kind].Cards[top].CostModifier[i]
+		}
 	}
 }
 
@@ -284,14 +337,51 @@ var Deck = []Card{
 	{"Knights", 4, Soldiers, Soldier, 1, []int{0,0,0,0}, 0, 0, 0, 0, 0, "Only build if right military building built.  Optional: may take opponent card up to 4, may -4 opponent attack; trash after use; +1 VP"},
 }
 
-var TestStock = [][]int{
-	1: {4}, // put the trading post on front to test bottoming out the discardPile
-	2: {23, 0, 1, 2, 3, 4, 5, 6, 7, 8, 39}, // QUICK KNIGHTS: to test end-of-turn discard
-	3: {23, 0, 1, 3, 9,  28, 29, 30, 31, 8, // STACK OF DOOM: play Fort
-		 17, 32,  18, 19, // adept 
-		 36, 10,  20, 21, // watch, archers
-		 37, 38,  11, 12, // archers, militia
-		 39},             // knights
-	4: {3, 7, 11, 15, 19, 5, 6, 8, 9, 10, 23, 27}, // NO BUILD OPTION:  On your second turn, you won't be able to build
-	5: {3, 7, 11, 15, 19, 5, 6, 8, 9, 10, 24, 28}, // ONLY ONE DISCARD: on your second turn, it should choose discard for you
-}
\ No newline at end of file
+var deckIndex = make(map[string]int)
+
+func init() {
+	for i, d := range Deck {
+		deckIndex[d.Name] = i
+	}
+}
+
+// DeckIndex gives the position in Deck of the given card, matching on the name since the
+// stock is built from copies of the deck
+func DeckIndex(c *Card) int {
+	index, ok := deckIndex[c.Name]
+	if !ok {
+		return -1
+	}
+	return index
+}
+
+// IndexByName gives the position in Deck of the card with the given name, ignoring case, or -1 if there
+// isn't one
+func IndexByName(name string) int {
+	for i, d := range Deck {
+		if strings.EqualFold(d.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// KindName gives the display name of a card kind, like "Farm"
+func KindName(kind int) string {
+	return cardType[kind]
+}
+
+// MaterialName gives the name of a material, like "wood"
+func MaterialName(material int) string {
+	return materials[material]
+}
+
+// KindByName finds the card kind for a display name like "Farm", or -1 if there isn't one
+func KindByName(name string) int {
+	for kind, n := range cardType {
+		if n == name {
+			return kind
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/chrislunt/warwick/player"
+)
+
+// the game the benchmarks copy and step, dealt from a fixed seed and played a few turns so there's
+// something on the table
+func benchGame(b *testing.B) *Game {
+	LogLevel, player.LogLevel = 0, 0
+	g := New(Options{Seed: 1})
+	for !g.Over && g.Turn < 10 {
+		g.PlayTurn()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	return g
+}
+
+var (
+	positionSink Position
+	hashSink     Hash
+)
+
+func BenchmarkPositionClone(b *testing.B) {
+	position := benchGame(b).Position()
+	for i := 0; i < b.N; i++ {
+		positionSink = position.Clone()
+	}
+}
+
+func BenchmarkGamePosition(b *testing.B) {
+	g := benchGame(b)
+	for i := 0; i < b.N; i++ {
+		positionSink = g.Position()
+	}
+}
+
+func BenchmarkGameSetPosition(b *testing.B) {
+	g := benchGame(b)
+	position, scratch := g.Position(), g.Clone()
+	for i := 0; i < b.N; i++ {
+		scratch.SetPosition(position)
+	}
+}
+
+func BenchmarkGameClone(b *testing.B) {
+	g := benchGame(b)
+	for i := 0; i < b.N; i++ {
+		g.Clone()
+	}
+}
+
+// restoring from a saved State is the slow way to copy a game, for comparison
+func BenchmarkRestore(b *testing.B) {
+	state := benchGame(b).State()
+	for i := 0; i < b.N; i++ {
+		Restore(state, nil)
+	}
+}
+
+func BenchmarkPositionHash(b *testing.B) {
+	position := benchGame(b).Position()
+	for i := 0; i < b.N; i++ {
+		hashSink = position.Hash()
+	}
+}
+
+// BenchmarkSearcherApply walks down the first option of each decision through a turn, then takes them all
+// back and walks down again, which is what a search does all the time.  Each step is one Apply, and the
+// Undos on the way back.
+func BenchmarkSearcherApply(b *testing.B) {
+	searcher := NewSearcher(benchGame(b), nil)
+	for i := 0; i < b.N; i++ {
+		if len(searcher.Line()) > 0 && (searcher.TurnStart() || searcher.Decision() == nil) {
+			for len(searcher.Line()) > 0 {
+				searcher.Undo()
+			}
+		}
+		searcher.Apply(0)
+	}
+}
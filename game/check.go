@@ -0,0 +1,134 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+Check looks for anything in the game that can't be right whatever the players did: a card in two places or
+in none, other than the cards thrown out of the game with a redrawn hand, a pile or hand that has lost
+count, a stack out of order, or a tableau whose powers don't match the cards on top.  The rules broken come back in an error that starts with the name of the rule, like
+"cards: ...", so two failures can be told apart.  It can be called at any decision, not just between turns.
+*/
+func (g *Game) Check() error {
+	where := make(map[*card.Card]string, len(g.cards))
+	place := func(c *card.Card, at string) error {
+		if _, ok := g.ids[c]; !ok {
+			return fmt.Errorf("cards: %s in %s isn't one of the game's cards", c.Name, at)
+		}
+		if before, ok := where[c]; ok {
+			return fmt.Errorf("cards: the same %s is in %s and in %s", c.Name, before, at)
+		}
+		where[c] = at
+		return nil
+	}
+	pile := func(h *card.Hand, name string) error {
+		if h.PullPos < -1 || h.PullPos >= len(h.Cards) {
+			return fmt.Errorf("piles: the %s has its top at %d, with room for %d", name, h.PullPos, len(h.Cards))
+		}
+		for i := 0; i <= h.PullPos; i++ {
+			if h.Cards[i] == nil {
+				return fmt.Errorf("piles: the %s has a gap at %d, under its top at %d", name, i, h.PullPos)
+			}
+			if err := place(h.Cards[i], "the "+name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := pile(&g.Stock, "stock"); err != nil {
+		return err
+	}
+	if err := pile(&g.DiscardPile, "discard pile"); err != nil {
+		return err
+	}
+	if err := pile(&g.Trash, "trash"); err != nil {
+		return err
+	}
+
+	for id, p := range g.Players {
+		held := 0
+		if len(p.Hand.Cards) != p.Hand.Max {
+			return fmt.Errorf("hands: player %d's hand has %d slots, not %d", id, len(p.Hand.Cards), p.Hand.Max)
+		}
+		for _, c := range p.Hand.Cards {
+			if c != nil {
+				held++
+				if err := place(c, fmt.Sprintf("player %d's hand", id)); err != nil {
+					return err
+				}
+			}
+		}
+		if held != p.Hand.Count {
+			return fmt.Errorf("hands: player %d holds %d cards but counts %d", id, held, p.Hand.Count)
+		}
+		if len(p.Tableau.Storage) != 2 {
+			return fmt.Errorf("storage: player %d has %d storage spots", id, len(p.Tableau.Storage))
+		}
+		for _, c := range p.Tableau.Storage {
+			if c != nil {
+				if err := place(c, fmt.Sprintf("player %d's storage", id)); err != nil {
+					return err
+				}
+			}
+		}
+		for kind, stack := range p.Tableau.Stack {
+			if stack == nil {
+				continue
+			}
+			name := fmt.Sprintf("player %d's %s stack", id, card.KindName(kind))
+			if stack.PullPos < 0 || stack.PullPos >= len(stack.Cards) || stack.Cards[stack.PullPos] == nil {
+				return fmt.Errorf("stacks: %s has no card on top, at %d", name, stack.PullPos)
+			}
+			for cost, c := range stack.Cards {
+				if c == nil {
+					if cost < stack.PullPos && cost > 0 && stack.Cards[cost-1] != nil {
+						return fmt.Errorf("stacks: %s has a gap at %d, under its top at %d", name, cost, stack.PullPos)
+					}
+					continue
+				}
+				if cost > stack.PullPos {
+					return fmt.Errorf("stacks: %s has %s above its top", name, c.Name)
+				}
+				if c.Kind != kind || c.Cost != cost {
+					return fmt.Errorf("stacks: %s has %s in the spot for cost %d", name, c, cost)
+				}
+				if err := place(c, name); err != nil {
+					return err
+				}
+			}
+		}
+		// the powers should be what the cards on top give
+		want := card.Tableau{Stack: p.Tableau.Stack}
+		want.Recount()
+		got := *p.Tableau
+		for i := range want.Discounts {
+			if i >= len(got.Discounts) || got.Discounts[i] != want.Discounts[i] {
+				return fmt.Errorf("powers: player %d has discounts %v, the cards on top give %v", id, got.Discounts, want.Discounts)
+			}
+		}
+		if got.Fill != want.Fill || got.BuildBonus != want.BuildBonus || got.DrawFromDiscardPower != want.DrawFromDiscardPower ||
+			got.TrashBonus != want.TrashBonus || got.DrawBonus != want.DrawBonus || got.AttackBonus != want.AttackBonus {
+			return fmt.Errorf("powers: player %d has fill %d, builds %d, discard draws %d, trash %d, draws %d, attack %d, the cards on top give %d, %d, %d, %d, %d, %d",
+				id, got.Fill, got.BuildBonus, got.DrawFromDiscardPower, got.TrashBonus, got.DrawBonus, got.AttackBonus,
+				want.Fill, want.BuildBonus, want.DrawFromDiscardPower, want.TrashBonus, want.DrawBonus, want.AttackBonus)
+		}
+	}
+
+	var missing []string
+	for i := range g.cards {
+		if _, ok := where[&g.cards[i]]; !ok {
+			missing = append(missing, g.cards[i].Name)
+		}
+	}
+	if len(missing) != g.out {
+		return fmt.Errorf("cards: %d missing (%s), with %d thrown out of the game with redrawn hands", len(missing), strings.Join(missing, ", "), g.out)
+	}
+	if g.ToMove != 0 && g.ToMove != 1 {
+		return fmt.Errorf("turns: it's player %d's turn", g.ToMove)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// TestConsole plays a human's turn at a plain console from a script of answers, one of them wrong, and
+// checks what they were shown and what they did
+func TestConsole(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	var out strings.Builder
+	console := player.NewConsole(strings.NewReader("build cathedral\nfowlery\n2\n"), &out)
+	console.Plain = true
+	g := New(Options{Seed: 1, Seats: []Seat{{Human: true, UI: console}}, Scenario: &Scenario{
+		Seats: [2]SeatScenario{{Hand: []string{"Fowlery", "Sawmill", "Chapel", "Walls", "Armory"}}},
+	}})
+	g.PlayTurn()
+
+	shown := out.String()
+	for _, want := range []string{
+		" WARWICK   turn 1",
+		"-=* BUILD *=-",
+		"1. Fowlery(Farm 1 : wood): -1 to recruit soldier",
+		`there's nothing called "cathedral" to choose`,
+		"-=* DISCARD *=-",
+		"Fowlery(Farm 1 : wood) (You can't discard this card)",
+		"2. Chapel(Civic 1 : stone): +1 VP",
+	} {
+		if !strings.Contains(shown, want) {
+			t.Errorf("the console didn't show %q", want)
+		}
+	}
+	if strings.Contains(shown, "\x1b") {
+		t.Errorf("a plain console wrote escape codes")
+	}
+
+	p := g.Players[0]
+	if top := p.Tableau.Top(card.Farm); top == nil || top.Name != "Fowlery" {
+		t.Errorf("player 0 built %v, not Fowlery", top)
+	}
+	if g.DiscardPile.PullPos != 0 || g.DiscardPile.Cards[0].Name != "Chapel" {
+		t.Errorf("the discard pile has %d cards, not just the Chapel", g.DiscardPile.PullPos+1)
+	}
+	for _, c := range p.Hand.Cards {
+		if c != nil && (c.Name == "Fowlery" || c.Name == "Chapel") {
+			t.Errorf("player 0 still holds %s", c.Name)
+		}
+	}
+	if g.ToMove != 1 {
+		t.Errorf("it's player %d's turn, not player 1's", g.ToMove)
+	}
+}
@@ -0,0 +1,299 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// These are the decisions a player makes over a turn
+const (
+	DecideBuild     = iota // which card to build, or pass
+	DecideDiscards         // which cards to pay for the build with
+	DecideRedraw           // whether to trash the hand and draw again, when nothing was built
+	DecideAttack           // which of the opponent's cards to send a soldier for, or none
+	DecideDefend           // whether to throw a soldier in the way of an attack
+	DecideStore            // where to fill an open storage spot from
+	DecideTrash            // which card to trash with a Market, or pass
+	DecideDraw             // whether to draw the top of the discard, rather than the stock
+	DecideHandLimit        // which card to give up when over the hand limit
+)
+
+var decisionType = map[int]string{
+	DecideBuild:     "build",
+	DecideDiscards:  "discards",
+	DecideRedraw:    "redraw",
+	DecideAttack:    "attack",
+	DecideDefend:    "defend",
+	DecideStore:     "store",
+	DecideTrash:     "trash",
+	DecideDraw:      "draw",
+	DecideHandLimit: "hand limit",
+}
+
+// DecisionName gives the display name of a decision type, like "build"
+func DecisionName(decision int) string {
+	return decisionType[decision]
+}
+
+// An Option is one of the legal answers to a decision.  Only the fields that go with the decision are set.
+type Option struct {
+	Label string
+	Pos   player.Pos   // the card, for build, store, trash and hand limit.  From is NoCard to pass.
+	Poses []player.Pos // the cards, for discards
+	Kind  int          // the kind to take, for attack, -1 for no attack
+	Yes   bool         // for redraw, defend, and draw where it means take the discard
+}
+
+func (o Option) String() string {
+	return o.Label
+}
+
+// A Decision is put to a Chooser with every legal option.  Card is the card it's about, where there is
+// one: the card being built for discards, the attacking soldier for defend, the top of the discard for draw.
+type Decision struct {
+	Type    int
+	Seat    int
+	Card    *card.Card
+	Kind    int // the kind of card under attack, for defend
+	Options []Option
+	Rules   *Rules        // the rules the game is played by
+	player  player.Player // who's deciding
+	game    *Game
+}
+
+func (d Decision) String() string {
+	return fmt.Sprintf("player %d %s", d.Seat, decisionType[d.Type])
+}
+
+// Forfeit gives up the game for the deciding seat, for an agent that can't go on.  The rest of the turn
+// plays out, so the agent should still answer, with the first option.
+func (d Decision) Forfeit(reason string) {
+	d.game.Forfeit(d.Seat, reason)
+}
+
+/*
+A Chooser makes the decisions for a seat in place of the player's own methods.  Choose gets what the seat
+can see and the decision, and gives back an index into d.Options.  Seats without a Chooser play as their
+Player always has.  A Chooser never sees more than the seat may know, so anything can play a seat: a bot
+program, or someone at the other end of a connection.
+*/
+type Chooser interface {
+	Choose(view *player.PlayerView, d Decision) int
+}
+
+// A Chooser that's also a Finisher is told when a game it played in is over, once for each seat it played,
+// with the VP and the winner, -1 for a tie
+type Finisher interface {
+	Finish(view *player.PlayerView, vp []int, winner int)
+}
+
+/*
+An Overseer decides for a seat with the whole game in front of it, hidden cards and all.  It's for tools that
+are allowed to see everything, like the review playing out the alternatives, so it's never given to a seat,
+only set on the game itself in Game.Overseers.  It comes before the seat's Chooser.
+*/
+type Overseer interface {
+	Oversee(g *Game, d Decision) int
+}
+
+/*
+decide puts the decision to the seat's Chooser if it has one, otherwise to the player.  The options are only
+worked out when they're needed: for a Chooser, for a human's hints, or to record the decision in the log.
+A recorded decision is played as the option matching the player's answer, so that playing the log back
+gives the same game.
+*/
+func (g *Game) decide(d Decision, options func() []Option, native func() Option) Option {
+	d.player, d.game, d.Rules = g.Players[d.Seat], g, &g.Rules
+	chooser, overseer := g.Choosers[d.Seat], g.Overseers[d.Seat]
+	human := d.player.Hint != nil
+	if chooser == nil && overseer == nil && !human && !g.options.Record {
+		return native()
+	}
+	d.Options = options()
+	var choice int
+	if chooser != nil || overseer != nil {
+		if overseer != nil {
+			choice = overseer.Oversee(g, d)
+		} else {
+			choice = chooser.Choose(d.player.PlayerView, d)
+		}
+		if choice < 0 || choice >= len(d.Options) {
+			panic(fmt.Sprintf("%s: option %d of %d", d, choice, len(d.Options)))
+		}
+	} else {
+		if human {
+			// keep the options to hand, in case they ask for a hint
+			g.pending = &d
+			defer func() { g.pending = nil }()
+		}
+		answer := native()
+		if !g.options.Record {
+			return answer
+		}
+		choice = match(d, answer)
+	}
+	if g.options.Record {
+		g.record(Event{Type: Chose, Seat: d.Seat, Decision: d.Type, Choice: choice, Options: len(d.Options), Label: d.Options[choice].Label})
+	}
+	return d.Options[choice]
+}
+
+// match finds the option a player's own answer is.  Two copies of a card are the same option, so cards are
+// matched on their name.
+func match(d Decision, answer Option) int {
+	for i, option := range d.Options {
+		switch d.Type {
+		case DecideAttack:
+			if option.Kind == answer.Kind {
+				return i
+			}
+		case DecideRedraw, DecideDefend, DecideDraw:
+			if option.Yes == answer.Yes {
+				return i
+			}
+		case DecideDiscards:
+			if option.Label == discardLabel(d.player, answer.Poses) {
+				return i
+			}
+		default:
+			if option.Pos.From != answer.Pos.From {
+				continue
+			}
+			if option.Pos.From == player.NoCard || option.Pos.From == player.FromStock || option.Pos.From == player.FromDiscard {
+				return i
+			}
+			if d.player.CardByPos(option.Pos).Name == d.player.CardByPos(answer.Pos).Name {
+				return i
+			}
+		}
+	}
+	panic(fmt.Sprintf("%s: the answer isn't one of the options", d))
+}
+
+var pass = Option{Label: "pass", Pos: player.Pos{From: player.NoCard, Index: -1}, Kind: -1}
+
+// the cards the player holds, in the hand and storage, each with where it is
+type held struct {
+	pos  player.Pos
+	card *card.Card
+}
+
+func holding(p player.Player, storage bool) (cards []held) {
+	for id, c := range p.Hand.Cards {
+		if c != nil {
+			cards = append(cards, held{player.Pos{From: player.FromHand, Index: id}, c})
+		}
+	}
+	if storage {
+		for id, c := range p.Tableau.Storage {
+			if c != nil {
+				cards = append(cards, held{player.Pos{From: player.FromStorage, Index: id}, c})
+			}
+		}
+	}
+	return
+}
+
+func (h held) String() string {
+	if h.pos.From == player.FromStorage {
+		return fmt.Sprintf("stored %s", h.card.Name)
+	}
+	return h.card.Name
+}
+
+func buildOptions(p player.Player) []Option {
+	options := []Option{pass}
+	for _, h := range holding(p, legalBuildFrom[player.FromStorage]) {
+		if p.CanBuild(h.pos) {
+			options = append(options, Option{Label: "build " + h.String(), Pos: h.pos, Kind: -1})
+		}
+	}
+	return options
+}
+
+// discardOptions gives every way to pay cost cards for the build.  Two copies of the same card are the
+// same payment, so those are only offered once.
+func discardOptions(p player.Player, protected player.Pos, cost int) (options []Option) {
+	var cards []held
+	for _, h := range holding(p, p.SpendStorage) {
+		if h.pos != protected {
+			cards = append(cards, h)
+		}
+	}
+	seen := make(map[string]bool)
+	var pick func(from int, chosen []held)
+	pick = func(from int, chosen []held) {
+		if len(chosen) == cost {
+			poses := make([]player.Pos, cost)
+			for i, h := range chosen {
+				poses[i] = h.pos
+			}
+			label := discardLabel(p, poses)
+			if !seen[label] {
+				seen[label] = true
+				options = append(options, Option{Label: label, Poses: poses, Kind: -1})
+			}
+			return
+		}
+		for i := from; i <= len(cards)-(cost-len(chosen)); i++ {
+			pick(i+1, append(chosen, cards[i]))
+		}
+	}
+	pick(0, nil)
+	return
+}
+
+func discardLabel(p player.Player, poses []player.Pos) string {
+	names := make([]string, len(poses))
+	for i, pos := range poses {
+		names[i] = held{pos, p.CardByPos(pos)}.String()
+	}
+	sort.Strings(names)
+	return "discard " + strings.Join(names, ", ")
+}
+
+func yesNo(yes, no string) []Option {
+	return []Option{{Label: no, Kind: -1}, {Label: yes, Yes: true, Kind: -1}}
+}
+
+// attackOptions follows the rule that a Defensive building must be taken before anything else
+func attackOptions(attackPower int, opponent player.Player) []Option {
+	options := []Option{{Label: "no attack", Kind: -1}}
+	for kind := 0; kind <= 9; kind++ {
+		if opponent.Tableau.Stack[card.Defensive] != nil && kind != card.Defensive {
+			continue
+		}
+		if top := opponent.TopCard(kind); top != nil && attackPower >= top.Cost {
+			options = append(options, Option{Label: "take " + top.Name, Kind: kind})
+		}
+	}
+	return options
+}
+
+func (g *Game) storeOptions(p player.Player) (options []Option) {
+	if g.Stock.PullPos > -1 {
+		options = append(options, Option{Label: "store from the stock", Pos: player.Pos{From: player.FromStock}, Kind: -1})
+	}
+	if g.DiscardPile.PullPos > -1 {
+		label := fmt.Sprintf("store %s from the discard", g.DiscardPile.Cards[g.DiscardPile.PullPos].Name)
+		options = append(options, Option{Label: label, Pos: player.Pos{From: player.FromDiscard}, Kind: -1})
+	}
+	return append(options, cardOptions("store", p, false)...)
+}
+
+// cardOptions offers each different card held, for trashing or giving up
+func cardOptions(verb string, p player.Player, storage bool) (options []Option) {
+	seen := make(map[string]bool)
+	for _, h := range holding(p, storage) {
+		label := verb + " " + h.String()
+		if !seen[label] {
+			seen[label] = true
+			options = append(options, Option{Label: label, Pos: h.pos, Kind: -1})
+		}
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+Determinize deals the cards the seat can't see at random between the stock and the opponent's hand, so a
+search can play out one guess at the hidden cards.  Cards the seat saw go into the opponent's hand stay
+there.
+*/
+func (g *Game) Determinize(seat int, rng *rand.Rand) {
+	hand := g.Players[1-seat].Hand
+	known := make(map[*card.Card]bool)
+	if tracker := g.Players[seat].Tracker; tracker != nil {
+		for _, c := range tracker.OpponentHolds {
+			known[c] = true
+		}
+	}
+	var pool []*card.Card
+	var slots []int
+	for i, c := range hand.Cards {
+		if c != nil && !known[c] {
+			pool = append(pool, c)
+			slots = append(slots, i)
+		}
+	}
+	pool = append(pool, g.Stock.Cards[:g.Stock.PullPos+1]...)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	for j, i := range slots {
+		hand.Cards[i] = pool[j]
+	}
+	copy(g.Stock.Cards, pool[len(slots):])
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// These are the kinds of things that get recorded in the game log
+const Built = 0
+const Stole = 1
+const Redrew = 2
+const Defended = 3
+const Chose = 4 // only recorded when Options.Record is set
+const Forfeit = 5
+
+var eventType = map[int]string{
+	Built:    "built",
+	Stole:    "stole",
+	Redrew:   "redrew",
+	Defended: "defended with",
+	Chose:    "chose",
+	Forfeit:  "forfeited",
+}
+
+/*
+An Event is one entry in the game log.  Card is the card built or taken, where there is one.  A Chose event
+is a decision, with the index of the option taken out of how many there were, so the game can be played
+back from its start.
+*/
+type Event struct {
+	Turn     int
+	Seat     int
+	Type     int
+	Card     *card.Card
+	Upgrade  bool
+	Decision int    `json:",omitempty"`
+	Choice   int    `json:",omitempty"`
+	Options  int    `json:",omitempty"`
+	Label    string `json:",omitempty"`
+}
+
+func (e Event) String() string {
+	if e.Type == Chose {
+		return fmt.Sprintf("turn %d: player %d %s: %s", e.Turn, e.Seat, decisionType[e.Decision], e.Label)
+	}
+	if e.Type == Forfeit {
+		return fmt.Sprintf("turn %d: player %d forfeited: %s", e.Turn, e.Seat, e.Label)
+	}
+	if e.Card == nil {
+		return fmt.Sprintf("turn %d: player %d %s", e.Turn, e.Seat, eventType[e.Type])
+	}
+	return fmt.Sprintf("turn %d: player %d %s %s", e.Turn, e.Seat, eventType[e.Type], e.Card)
+}
+
+func (g *Game) record(e Event) {
+	e.Turn = g.Turn
+	g.Events = append(g.Events, e)
+}
@@ -0,0 +1,656 @@
+/*
+Package game runs a game of Warwick between two players.  The rules themselves are described at the top of
+warwick.go; this package holds the piles, the turn loop and a log of what happened, so a game can be played
+at the console or simulated many times over.
+*/
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+var LogLevel = 2
+
+func log(level int, message string) {
+	if LogLevel >= level {
+		fmt.Println(message)
+	}
+}
+
+// Options describe how to set up a game
+type Options struct {
+	Seed      int64     // the seed used to shuffle the stock
+	Seats     []Seat    // who plays each side, missing seats get the default computer player
+	Scenario  *Scenario // nil for a fully shuffled stock
+	TurnLimit int       // you can use this to cut a game short for dev purposes, 0 is no limit
+	Rules     *Rules    // nil for DefaultRules
+	Analysis  bool      // show human players a hint at every decision
+	Record    bool      // keep every decision in the game log, so the game can be played back and reviewed
+}
+
+type Game struct {
+	Players     []player.Player
+	Stock       card.Hand
+	DiscardPile card.Hand
+	Trash       card.Hand
+	StockSize   int
+	Turn        int
+	ToMove      int // the seat whose turn it is
+	Over        bool
+	Ending      int // why the game is over, NotOver until it is
+	Events      []Event
+	Rules       Rules
+	Clock       *player.Clock
+	Start       *State      // where the game started from, when decisions are recorded
+	Choosers    [2]Chooser  // make a seat's decisions in place of the player, nil to leave them to the player
+	Overseers   [2]Overseer // make a seat's decisions seeing the whole game, for tools like the review
+	Forfeited   int         // the seat that gave up the game by breaking the rules, -1 if neither
+	options     Options
+	pending     *Decision            // the decision in front of a human, for hints
+	news        [2]string            // what each seat has been told since their last turn
+	cards       []card.Card          // every card in the game, the first copy of the deck and then the second
+	ids         map[*card.Card]uint8 // each card's index in cards, for Position
+	out         int                  // how many cards are out of the game, thrown in with a redrawn hand
+}
+
+// set up rules about where you can get cards from for different actions
+var legalBuildFrom = map[int]bool{
+	player.FromHand:    true,
+	player.FromStorage: true,
+	player.FromStock:   false,
+	player.FromDiscard: false,
+}
+
+// store fills the open storage spots the Storage building just built gives the player
+func (g *Game) store(storePower int, id int, phase int) {
+	currentPlayer := g.Players[id]
+	var topSpot int
+	bottomSpot := 0
+	switch {
+	case storePower == 1 || storePower == 2:
+		// the player may choose from hand, discard or stock to fill the storage
+		// if the spot is open, you may refill it
+		topSpot = 0
+	case storePower == 3 || storePower == 4:
+		// a second storage spot opens, fill from stock, discard or hand
+		// for a 4, refill either open storage spots
+		topSpot = 1
+	}
+	if g.Rules.StorageFill == StorageCardText && storePower == 3 {
+		// the Storehouse only fills the spot it opens
+		bottomSpot = 1
+	}
+	for spot := bottomSpot; spot <= topSpot; spot++ {
+		if currentPlayer.Tableau.Storage[spot] != nil {
+			continue
+		}
+		options := g.storeOptions(currentPlayer)
+		if len(options) == 0 {
+			continue
+		}
+		choice := g.decide(Decision{Type: DecideStore, Seat: id}, func() []Option { return options }, func() Option {
+			return Option{Pos: currentPlayer.ChooseStore(phase)}
+		})
+		storeCard := currentPlayer.TakeToStore(choice.Pos, &g.Stock, &g.DiscardPile)
+		if storeCard == nil {
+			continue
+		}
+		log(1, fmt.Sprintf("Stored in storage %d: %s", spot, storeCard))
+		g.tell(1-id, "Opponent stored %s", storeCard.Name)
+		currentPlayer.Tableau.Storage[spot] = storeCard
+	}
+}
+
+func buildStock(seed int64, l *layout) (stock card.Hand, stockSize int, allCards []card.Card) {
+	rng := rand.New(rand.NewSource(seed))
+
+	// double the deck.  This is the canonical reference of all cards.
+	allCards = append(card.Deck[:], card.Deck[:]...)
+	stockSize = len(allCards)
+
+	// the stock, which can shrink, is a reference to all cards
+	stock.Cards = make([]*card.Card, stockSize)
+
+	/* There are two ways we could randomize, one would be randomize the stock and keep a pointer of where we currently are,
+	which has an up-front randomization cost, but all subsequent pulls are cheap.
+	*/
+	var permutation []int
+	if l != nil {
+		// shuffle what the scenario leaves out, and stack its stock on top
+		var rest []int
+		for id := range allCards {
+			if !l.laid[id] {
+				rest = append(rest, id)
+			}
+		}
+		for _, i := range rng.Perm(len(rest)) {
+			permutation = append(permutation, rest[i])
+		}
+		for i := len(l.stock) - 1; i >= 0; i-- {
+			permutation = append(permutation, int(l.stock[i]))
+		}
+	} else {
+		permutation = rng.Perm(stockSize)
+	}
+	for i, v := range permutation {
+		stock.Cards[i] = &allCards[v]
+	}
+	stock.PullPos = len(permutation) - 1 // the position representing the current position to draw from
+	return
+}
+
+/*
+New shuffles the stock and deals each player their opening hand, or sets the game up as the scenario says.
+The scenario should have been checked, since New panics if it can't be set up.
+*/
+func New(options Options) *Game {
+	g := &Game{options: options, Rules: DefaultRules(), Forfeited: -1}
+	if options.Rules != nil {
+		g.Rules = *options.Rules
+	}
+	var l *layout
+	if options.Scenario != nil {
+		var err error
+		if l, err = options.Scenario.lay(); err != nil {
+			panic(err)
+		}
+	}
+	g.Stock, g.StockSize, g.cards = buildStock(options.Seed, l)
+	g.ids = make(map[*card.Card]uint8, len(g.cards))
+	for i := range g.cards {
+		g.ids[&g.cards[i]] = uint8(i)
+	}
+
+	g.DiscardPile.Cards = make([]*card.Card, g.StockSize)
+	g.DiscardPile.PullPos = -1
+
+	g.Trash.Cards = make([]*card.Card, g.StockSize)
+	g.Trash.PullPos = -1
+	// trash is never pulled from, the pull position just tracks the top
+
+	g.Players = make([]player.Player, 2)
+
+	// initialize the players
+	var console *player.Console // humans with no UI of their own share the terminal
+	for id := range g.Players {
+		g.Players[id].PlayerView = &player.PlayerView{Seat: id}
+		g.Players[id].Hand = &card.Hand{}
+		g.Players[id].Hand.Limit = 5
+		g.Players[id].Hand.Max = 7
+		// create the hand with an extra 2 slots beyond the limit, which could happen
+		// if you use a soldier and then do an exchange
+		g.Players[id].Hand.Cards = make([]*card.Card, g.Players[id].Hand.Max)
+		// do the initial draw of 5 cards, unless the scenario gives the hand
+		if l == nil || l.seats[id].dealt {
+			g.Stock.RandomPull(5, g.Players[id].Hand)
+		}
+		// initize the Tableaus.  The Tableau is a map indexed by a card type constant
+		// the map points to a small hand which is the potential stack of cards as someone upgrades
+		// there are 10 types of cards, plus 2 storage spots so each slot must be initialized
+		g.Players[id].Tableau = &card.Tableau{}
+		g.Players[id].Tableau.Stack = make(map[int]*card.Hand)
+		g.Players[id].Tableau.Discounts = make([]int, 4)
+		g.Players[id].Tableau.BuildBonus = 0
+		g.Players[id].Tableau.AttackBonus = 0
+		g.Players[id].Tableau.Storage = make([]*card.Card, 2)
+		var seat Seat
+		if id < len(options.Seats) {
+			seat = options.Seats[id]
+		}
+		g.Players[id].Human = seat.Human
+		g.Players[id].SpendStorage = g.Rules.SpendStorage
+		g.Players[id].Strategy = seat.Strategy
+		g.Players[id].Personality = seat.Personality
+		if g.Players[id].Strategy == nil {
+			g.Players[id].Strategy = player.DefaultStrategy()
+		}
+		g.Choosers[id] = seat.Chooser
+		if seat.Human {
+			g.Players[id].Hint = g.hint
+			g.Players[id].Analysis = options.Analysis
+			seatID := id
+			g.Players[id].News = func() string { return g.news[seatID] }
+			g.Players[id].UI = seat.UI
+			if seat.UI == nil {
+				if console == nil {
+					console = player.NewConsole(os.Stdin, os.Stdout)
+				}
+				g.Players[id].UI = console
+			}
+		}
+	}
+	g.Clock = &player.Clock{Stock: g.stockCount, TurnCap: g.Rules.TurnCap}
+	for id := range g.Players {
+		// each player sees the face up piles and the other's tableau, but only counts the stock and their hand
+		other := g.Players[1-id]
+		view := g.Players[id].PlayerView
+		view.Opponent = other.Tableau
+		view.DiscardPile = &g.DiscardPile
+		view.Trash = &g.Trash
+		view.Stock = g.stockCount
+		view.OpponentHand = func() int { return other.Hand.Count }
+		view.Tracker = player.NewTracker()
+		view.Clock = g.Clock
+	}
+	if l != nil {
+		g.setUp(l)
+	}
+	if options.Record {
+		g.RecordFromHere()
+	}
+	return g
+}
+
+func (g *Game) stockCount() int {
+	return g.Stock.PullPos + 1
+}
+
+// Play runs turns until the game is over
+func (g *Game) Play() {
+	for !g.Over {
+		g.PlayTurn()
+	}
+}
+
+// PlayTurn plays the next player's turn, or ends the game if it's over
+func (g *Game) PlayTurn() {
+	if g.Over {
+		return
+	}
+	g.playTurn()
+	if g.Over {
+		g.finish()
+	}
+}
+
+// Why a game ended
+const (
+	NotOver      = iota
+	EndStock     // the stock ran out
+	EndBuilt     // a player built one of every kind of building
+	EndTurnCap   // the rules' turn cap, for safety
+	EndTurnLimit // Options.TurnLimit cut it short
+	EndForfeit   // a player forfeited
+)
+
+var endingName = map[int]string{
+	NotOver:      "not over",
+	EndStock:     "stock",
+	EndBuilt:     "built",
+	EndTurnCap:   "turn cap",
+	EndTurnLimit: "turn limit",
+	EndForfeit:   "forfeit",
+}
+
+// EndingName gives the short name of why a game ended, like "stock"
+func EndingName(ending int) string {
+	return endingName[ending]
+}
+
+func (g *Game) end(ending int) {
+	g.Over, g.Ending = true, ending
+}
+
+func (g *Game) playTurn() {
+	if g.ToMove == 0 {
+		// play until the deck runs out
+		// or until the first player fills everything in their table (soldier doesn't matter)
+		if g.Stock.PullPos < 0 {
+			g.end(EndStock)
+			return
+		}
+		if g.options.TurnLimit > 0 && g.Turn >= g.options.TurnLimit {
+			g.end(EndTurnLimit)
+			return
+		}
+		// for safety
+		// if you can't build any of the cards in your hand (because those positions are filled), you can get stuck
+		if g.Rules.TurnCap > 0 && g.Turn >= g.Rules.TurnCap {
+			log(1, fmt.Sprintf("The game went to %d turns--ending as a safety", g.Turn))
+			g.end(EndTurnCap)
+			return
+		}
+		g.Turn++
+		g.Clock.Turn = g.Turn
+	}
+	g.turn(g.ToMove)
+	// the news they've seen through their turn is old now
+	g.news[g.ToMove] = ""
+	if g.Over {
+		return
+	}
+	g.ToMove = 1 - g.ToMove
+	if g.ToMove == 0 {
+		log(1, "----END OF TURN----")
+	}
+}
+
+// turn plays out one player's turn
+func (g *Game) turn(id int) {
+	currentPlayer := g.Players[id]
+	opponent := g.Players[1-id]
+
+	// if we're coming back to this player and they already have 9 cards, it's time to stop
+	if currentPlayer.Tableau.Fill == 9 {
+		g.end(EndBuilt)
+		return
+		// there is an error here in that if player 1 goes out first, player 0 doesn't get another play
+	}
+	// the phase of the game comes from how close it is to the end
+	phase := g.Clock.Phase(g.Players[0].Tableau.Fill, g.Players[1].Tableau.Fill)
+
+	// turn order:
+	// 1. Build
+	// 2. Attack
+	// 3. Trash (with Market)
+	// 4. Draw up to 5 OR discard down to 5
+
+	// determine card to build, cost
+	// determine discards
+	// do build
+	builds := 0
+
+	// we check it each time, since if you build the card, you get to use it immediately
+	for builds < (currentPlayer.Tableau.BuildBonus + 1) {
+		choice := g.decide(Decision{Type: DecideBuild, Seat: id}, func() []Option {
+			return buildOptions(currentPlayer)
+		}, func() Option {
+			pos, _, _ := currentPlayer.PlayerChooses(legalBuildFrom, phase, builds)
+			return Option{Pos: pos}
+		})
+		buildPos := choice.Pos
+		if buildPos.From == player.NoCard {
+			break
+		}
+		built := currentPlayer.CardByPos(buildPos)
+		cost, upgrade := currentPlayer.BuildCost(built)
+		log(1, fmt.Sprintf("Player %d builds %s for %d", id, built, cost))
+		var discards []player.Pos
+		if cost > 0 {
+			discards = g.decide(Decision{Type: DecideDiscards, Seat: id, Card: built}, func() []Option {
+				return discardOptions(currentPlayer, buildPos, cost)
+			}, func() Option {
+				return Option{Poses: currentPlayer.ChooseDiscards(buildPos, cost, phase)}
+			}).Poses
+			if LogLevel > 1 {
+				fmt.Println("Player", id, "discards:")
+				for _, pos := range discards {
+					fmt.Println(currentPlayer.CardByPos(pos))
+				}
+			}
+		}
+		if upgrade {
+			g.tell(1-id, "Opponent upgraded to %s%s", built.Name, paidWith(currentPlayer, discards))
+		} else {
+			g.tell(1-id, "Opponent built %s%s", built.Name, paidWith(currentPlayer, discards))
+		}
+		currentPlayer.Build(buildPos, discards, &g.DiscardPile, upgrade)
+		g.record(Event{Type: Built, Seat: id, Card: built, Upgrade: upgrade})
+		// if it's storage, you get a chance to place a card
+		if built.Kind == card.Storage {
+			g.store(built.Cost, id, phase)
+		}
+
+		log(2, fmt.Sprintf("currentPlayer %d has %d cards left", id, currentPlayer.Hand.Count))
+		builds++
+	}
+
+	// When they don't build, and they have cards, check if they'd like to trash and redraw
+	if builds == 0 && currentPlayer.Hand.Count > 0 {
+		preResetCount := currentPlayer.Hand.Count
+		// if the computer player can't build, but they have a full hand, they will get stuck.  Invoke the hand reset rule
+		redraw := g.Rules.RedrawOnFullHand && currentPlayer.Hand.Count == currentPlayer.Hand.Limit
+		if !redraw {
+			redraw = g.decide(Decision{Type: DecideRedraw, Seat: id}, func() []Option {
+				return yesNo("redraw", "keep the hand")
+			}, func() Option {
+				return Option{Yes: currentPlayer.Human && currentPlayer.HumanWantsRedraw()}
+			}).Yes
+		}
+		if redraw {
+			if g.redrawPile() == nil {
+				g.out += preResetCount
+			}
+			currentPlayer.Hand.Reset(g.redrawPile())
+			g.Stock.RandomPull(preResetCount, currentPlayer.Hand)
+			log(1, fmt.Sprintf("Player %d dumps their hand and redraws", id))
+			g.tell(1-id, "Opponent threw in their hand and drew %d new cards", preResetCount)
+			g.record(Event{Type: Redrew, Seat: id})
+			// if you recycle your hand, you don't get to do any builds, attacks, exchanges
+			return
+		}
+	}
+
+	// ------ Attack --------- //
+	if attacker := currentPlayer.TopCard(card.Soldiers); attacker != nil {
+		attackPower := attacker.Cost + currentPlayer.Tableau.AttackBonus
+		steal := g.decide(Decision{Type: DecideAttack, Seat: id, Card: attacker}, func() []Option {
+			return attackOptions(attackPower, opponent)
+		}, func() Option {
+			return Option{Kind: currentPlayer.ChooseAttack(phase)}
+		}).Kind // steal is a card kind
+		if steal != -1 {
+			g.attack(id, steal, phase)
+		}
+	}
+
+	// ------- TRASH --------- //
+	cardsTrashed := 0
+	// TrashBonus measures the amount of cards you can trash in order to draw a new one
+	if currentPlayer.Tableau.TrashBonus > 0 && currentPlayer.Hand.Count > 0 {
+		if g.Choosers[id] == nil && g.Overseers[id] == nil && !currentPlayer.Human && !g.options.Record {
+			trashPoses := currentPlayer.ChooseTrash(phase)
+			for _, pos := range trashPoses {
+				if pos.From != player.NoCard {
+					g.tell(1-id, "Opponent trashed %s", currentPlayer.CardByPos(pos).Name)
+				}
+			}
+			cardsTrashed = currentPlayer.TrashCards(trashPoses, &g.Trash)
+		} else {
+			// one card at a time, until they pass
+			for cardsTrashed < currentPlayer.Tableau.TrashBonus {
+				options := append([]Option{pass}, cardOptions("trash", currentPlayer, currentPlayer.SpendStorage)...)
+				if len(options) == 1 {
+					break
+				}
+				choice := g.decide(Decision{Type: DecideTrash, Seat: id}, func() []Option { return options }, func() Option {
+					return Option{Pos: currentPlayer.ChooseTrash(phase)[0]}
+				})
+				if choice.Pos.From == player.NoCard {
+					break
+				}
+				g.tell(1-id, "Opponent trashed %s", currentPlayer.CardByPos(choice.Pos).Name)
+				cardsTrashed += currentPlayer.TrashCards([]player.Pos{choice.Pos}, &g.Trash)
+			}
+		}
+	}
+	// you must trash card to get the draw bonus under the current rules
+	if (currentPlayer.Tableau.DrawBonus > 0) && (cardsTrashed > 0) {
+		g.Stock.RandomPull(currentPlayer.Tableau.DrawBonus, currentPlayer.Hand)
+		log(1, fmt.Sprintf("Player %d bonus draws %d", id, currentPlayer.Tableau.DrawBonus))
+	}
+
+	// ------- DRAW --------- //
+	// see how many open spots there are in the hand.  This may not run at all
+	g.draw(id, phase)
+
+	// ------- DISCARD --------- //
+	g.discardToLimit(id, phase)
+}
+
+// tell adds to a human seat's news, what they're shown of what's happened since their last turn
+func (g *Game) tell(id int, format string, a ...interface{}) {
+	if g.Players[id].Human {
+		g.news[id] += fmt.Sprintf(format+"\n", a...)
+	}
+}
+
+// paidWith lists the cards a build is paid with, which go face up on the discard
+func paidWith(p player.Player, discards []player.Pos) string {
+	if len(discards) == 0 {
+		return ""
+	}
+	var names []string
+	for _, pos := range discards {
+		names = append(names, p.CardByPos(pos).Name)
+	}
+	return ", paying with " + strings.Join(names, ", ")
+}
+
+// attack sends the player's soldier after the opponent's card of the kind steal
+func (g *Game) attack(id int, steal int, phase int) {
+	currentPlayer := g.Players[id]
+	opponent := g.Players[1-id]
+	attacker := currentPlayer.TopCard(card.Soldiers)
+	attackPower := attacker.Cost + currentPlayer.Tableau.AttackBonus
+	// under the defend rule, the opponent may throw their own soldier in the way
+	if defender := opponent.TopCard(card.Soldiers); g.Rules.SoldiersDefend && defender != nil && steal != card.Soldiers {
+		defend := g.decide(Decision{Type: DecideDefend, Seat: 1 - id, Card: attacker, Kind: steal}, func() []Option {
+			return yesNo("defend with "+defender.Name, "let it through")
+		}, func() Option {
+			return Option{Yes: opponent.ChooseDefend(attacker, attackPower, steal, phase)}
+		}).Yes
+		if defend {
+			attackPower -= defender.Cost
+			log(1, fmt.Sprintf("Player %d defends with %s", 1-id, defender))
+			g.tell(id, "Opponent defended with %s", defender.Name)
+			g.record(Event{Type: Defended, Seat: 1 - id, Card: defender})
+			opponent.Tableau.RemoveTop(card.Soldiers, nil)
+			g.pile(g.Rules.SoldiersTo).Place(defender)
+		}
+	}
+	if attackPower >= opponent.TopCard(steal).Cost {
+		g.tell(1-id, "ALERT: Opponent used a %s to take your %s", attacker.Name, opponent.TopCard(steal).Name)
+		g.tell(id, "Your %s took their %s", attacker.Name, opponent.TopCard(steal).Name)
+		log(1, fmt.Sprintf("Player %d uses %s and takes opponent's %s", id, attacker, opponent.TopCard(steal)))
+		g.record(Event{Type: Stole, Seat: id, Card: opponent.TopCard(steal)})
+		// everyone sees the card go into the attacker's hand
+		opponent.Tracker.OpponentTook(opponent.TopCard(steal))
+		opponent.Tableau.RemoveTop(steal, currentPlayer.Hand)
+	} else {
+		log(1, fmt.Sprintf("Player %d's %s is driven off", id, attacker))
+		g.tell(1-id, "Opponent's %s went after your %s and was driven off", attacker.Name, opponent.TopCard(steal).Name)
+		g.tell(id, "Your %s was driven off", attacker.Name)
+	}
+	// then loose your attack card
+	currentPlayer.Tableau.RemoveTop(card.Soldiers, nil)
+	g.pile(g.Rules.SoldiersTo).Place(attacker)
+}
+
+// draw fills the hand from the stock, or the discard pile with a Market
+func (g *Game) draw(id int, phase int) {
+	currentPlayer := g.Players[id]
+	if currentPlayer.Tableau.DrawFromDiscardPower < 1 {
+		g.Stock.RandomPull(2, currentPlayer.Hand) // this will only pull up to the hand limit
+		return
+	}
+	// here we should use "drawFromDiscardPower" when it's greater than one
+	drawCount := currentPlayer.Hand.Max - currentPlayer.Hand.Count
+	if drawCount > 2 { // you can't draw more than 2
+		drawCount = 2
+	}
+	for ; drawCount > 0; drawCount-- {
+		if g.DiscardPile.PullPos == -1 { // the discard pile is empty, must pull from stock
+			g.Stock.RandomPull(1, currentPlayer.Hand)
+			continue
+		}
+		top := g.DiscardPile.Cards[g.DiscardPile.PullPos]
+		take := g.decide(Decision{Type: DecideDraw, Seat: id, Card: top}, func() []Option {
+			return yesNo("draw "+top.Name+" from the discard", "draw from the stock")
+		}, func() Option {
+			return Option{Yes: currentPlayer.WantsDiscard(top, phase)}
+		}).Yes
+		if !take {
+			if currentPlayer.Human {
+				// they've turned this card down, so pull the remaining cards from the stock
+				g.Stock.RandomPull(drawCount, currentPlayer.Hand)
+				return
+			}
+			g.Stock.RandomPull(1, currentPlayer.Hand)
+			continue
+		}
+		before := currentPlayer.Hand.Count
+		g.DiscardPile.TopPull(1, currentPlayer.Hand)
+		if currentPlayer.Hand.Count > before {
+			// the opponent sees the card go into the hand
+			g.Players[1-id].Tracker.OpponentTook(top)
+			g.tell(1-id, "Opponent drew %s from the discard", top.Name)
+		}
+	}
+}
+
+// discardToLimit has the player give up cards until they're down to the hand limit
+func (g *Game) discardToLimit(id int, phase int) {
+	currentPlayer := g.Players[id]
+	for currentPlayer.Hand.Count > currentPlayer.Hand.Limit {
+		log(2, fmt.Sprintf("=================== Player %d has %d cards =================", id, currentPlayer.Hand.Count))
+		choice := g.decide(Decision{Type: DecideHandLimit, Seat: id}, func() []Option {
+			return cardOptions("give up", currentPlayer, false)
+		}, func() Option {
+			// only cards in the hand count toward the limit
+			handOnly := [][]bool{nil, nil, {true, true}}
+			pos, _ := currentPlayer.LowestValueCard(phase, handOnly)
+			return Option{Pos: pos}
+		})
+		g.tell(1-id, "Opponent gave up %s, over the hand limit", currentPlayer.CardByPos(choice.Pos).Name)
+		currentPlayer.Hand.RemoveCard(choice.Pos.Index, g.pile(g.Rules.HandLimitTo))
+	}
+}
+
+// Seed is the seed the stock was shuffled with
+func (g *Game) Seed() int64 {
+	return g.options.Seed
+}
+
+// VictoryPoints gives the current score for each seat
+func (g *Game) VictoryPoints() (vp []int) {
+	vp = make([]int, len(g.Players))
+	for id, currentPlayer := range g.Players {
+		vp[id] = currentPlayer.VictoryPoints()
+	}
+	return
+}
+
+// Winner is the seat with the most VP, or -1 for a tie.  A seat that forfeited loses whatever the score.
+func (g *Game) Winner() int {
+	if g.Forfeited != -1 {
+		return 1 - g.Forfeited
+	}
+	vp := g.VictoryPoints()
+	if vp[0] == vp[1] {
+		return -1
+	} else if vp[0] < vp[1] {
+		return 1
+	}
+	return 0
+}
+
+// Forfeit ends the game as a loss for the seat, for an agent that broke the rules or ran out of time.  The
+// rest of the turn plays out, with the seat's Chooser expected to take the first option.  An agent forfeits
+// with Decision.Forfeit.
+func (g *Game) Forfeit(seat int, reason string) {
+	if g.Over {
+		return
+	}
+	log(1, fmt.Sprintf("Player %d forfeits: %s", seat, reason))
+	g.tell(1-seat, "Opponent forfeited: %s", reason)
+	g.record(Event{Type: Forfeit, Seat: seat, Label: reason})
+	g.Forfeited = seat
+	g.end(EndForfeit)
+}
+
+// finish lets any Choosers that want to know hear how the game came out
+func (g *Game) finish() {
+	vp, winner := g.VictoryPoints(), g.Winner()
+	for id, chooser := range g.Choosers {
+		if f, ok := chooser.(Finisher); ok {
+			f.Finish(g.Players[id].PlayerView, vp, winner)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// TestTrash trashes both opening hands, and the trash has to fill up from the bottom
+func TestTrash(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	g := New(Options{Seed: 1})
+	trashed := 0
+	for id := range g.Players {
+		hand := g.Players[id].Hand
+		for pos, c := range hand.Cards {
+			if c == nil {
+				continue
+			}
+			hand.RemoveCard(pos, &g.Trash)
+			if g.Trash.PullPos != trashed || g.Trash.Cards[trashed] != c {
+				t.Fatalf("trashed %s as card %d, the trash has its top at %d", c, trashed, g.Trash.PullPos)
+			}
+			trashed++
+		}
+	}
+	if trashed == 0 {
+		t.Fatal("no cards were dealt to trash")
+	}
+}
+
+// TestStoreFromEmpty fills storage with nothing in the discard pile, and then with nothing anywhere
+func TestStoreFromEmpty(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	g := New(Options{Seed: 1})
+	p := &g.Players[0]
+	if pos := p.ChooseStore(0); pos.From == player.NoCard {
+		t.Error("nothing was stored, with cards in the hand and the stock")
+	}
+	for pos, c := range p.Hand.Cards {
+		if c != nil {
+			p.Hand.RemoveCard(pos, nil)
+		}
+	}
+	g.Stock.PullPos = -1
+	if pos := p.ChooseStore(0); pos.From != player.NoCard {
+		t.Errorf("chose to store %+v, with nothing to store from", pos)
+	}
+}
+
+// TestHandLimit fills a hand past the limit with its best cards at the front and the worst card in storage,
+// and the cards given up have to come from the hand
+func TestHandLimit(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	g := New(Options{Seed: 1})
+	p := &g.Players[0]
+	cards := make([]*card.Card, p.Hand.Max+1)
+	for i := range cards {
+		cards[i] = g.Stock.Cards[g.Stock.PullPos]
+		g.Stock.PullPos--
+	}
+	sort.SliceStable(cards, func(i, j int) bool { return p.CardValue(cards[i], 0) > p.CardValue(cards[j], 0) })
+	copy(p.Hand.Cards, cards)
+	p.Hand.Count = p.Hand.Max
+	p.Tableau.Storage[0] = cards[p.Hand.Max]
+
+	g.discardToLimit(0, 0)
+	if p.Hand.Count != p.Hand.Limit {
+		t.Fatalf("the hand is down to %d cards, not %d", p.Hand.Count, p.Hand.Limit)
+	}
+	for i, c := range cards[:p.Hand.Limit] {
+		if p.Hand.Cards[i] != c {
+			t.Errorf("gave up %s, one of the best cards in the hand", c)
+		}
+	}
+	if p.Tableau.Storage[0] != cards[p.Hand.Max] {
+		t.Errorf("the stored %s is gone", cards[p.Hand.Max])
+	}
+}
+
+// TestDefend plays a warlord against a turtle, which defends anything it can, under the default rules where
+// no one may defend and then under the defend rule, where someone should
+func TestDefend(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	var seats []Seat
+	for _, spec := range []string{"warlord", "turtle"} {
+		seat, err := ParseSeat(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seats = append(seats, seat)
+	}
+	defend := DefaultRules()
+	defend.SoldiersDefend = true
+	defended := 0
+	for seed := int64(1); seed <= 50; seed++ {
+		for _, rules := range []*Rules{nil, &defend} {
+			g := New(Options{Seed: seed, Seats: seats, Rules: rules})
+			g.Play()
+			for _, e := range g.Events {
+				if e.Type != Defended {
+					continue
+				}
+				if rules == nil {
+					t.Errorf("seed %d: player %d defended with %s under the default rules", seed, e.Seat, e.Card)
+				}
+				defended++
+			}
+		}
+	}
+	if defended == 0 {
+		t.Error("no one defended in 50 games under the defend rule")
+	}
+}
+
+// the cards still in the game, wherever they are
+func countCards(g *Game) (count int) {
+	count = g.Stock.PullPos + 1 + g.DiscardPile.PullPos + 1 + g.Trash.PullPos + 1
+	for _, p := range g.Players {
+		count += p.Hand.Count
+		for _, c := range p.Tableau.Storage {
+			if c != nil {
+				count++
+			}
+		}
+		for _, stack := range p.Tableau.Stack {
+			if stack == nil {
+				continue
+			}
+			for _, c := range stack.Cards {
+				if c != nil {
+					count++
+				}
+			}
+		}
+	}
+	return
+}
+
+// TestRedraw plays games where hands get thrown in.  Under the default rules a redrawn hand goes out of the
+// game, under the redrawtrash rule it goes in the trash and every card is still somewhere at the end.
+func TestRedraw(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	trash := DefaultRules()
+	trash.RedrawToTrash = true
+	redrew := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		for _, rules := range []*Rules{nil, &trash} {
+			g := New(Options{Seed: seed, Rules: rules})
+			g.Play()
+			thrown := false
+			for _, e := range g.Events {
+				thrown = thrown || e.Type == Redrew
+			}
+			if !thrown {
+				continue
+			}
+			redrew++
+			count := countCards(g)
+			if rules == nil && count == g.StockSize {
+				t.Errorf("seed %d: a hand was redrawn and all %d cards are still in the game", seed, count)
+			} else if rules != nil && count != g.StockSize {
+				t.Errorf("seed %d: %d of the %d cards are left after redrawing into the trash", seed, count, g.StockSize)
+			}
+		}
+	}
+	if redrew == 0 {
+		t.Error("no one redrew in 20 games")
+	}
+}
+
+// replay makes the choices of a recorded game over again, in order
+type replay struct {
+	choices []int
+	made    int
+}
+
+func (r *replay) Choose(view *player.PlayerView, d Decision) int {
+	r.made++
+	return r.choices[r.made-1]
+}
+
+/*
+TestTracker plays games back from their log, with choosers that never count cards, and they have to come to
+the same position, down to the cards each player remembers the opponent holding.  A card taken off the
+discard, put back and taken again is still only remembered once.
+*/
+func TestTracker(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	for seed := int64(1); seed <= 10; seed++ {
+		g := New(Options{Seed: seed, Record: true})
+		g.Play()
+		r := &replay{}
+		for _, e := range g.Events {
+			if e.Type == Chose {
+				r.choices = append(r.choices, e.Choice)
+			}
+		}
+		back, err := Restore(*g.Start, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back.Choosers = [2]Chooser{r, r}
+		back.Play()
+		played, ended := back.Position(), g.Position()
+		if played.Hash() != ended.Hash() {
+			t.Errorf("seed %d: the game played back to another position, remembering %v and %v held, not %v and %v",
+				seed, played.Seats[0].Holds[:played.Seats[0].Held], played.Seats[1].Holds[:played.Seats[1].Held],
+				ended.Seats[0].Holds[:ended.Seats[0].Held], ended.Seats[1].Holds[:ended.Seats[1].Held])
+		}
+	}
+}
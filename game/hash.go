@@ -0,0 +1,196 @@
+package game
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A Hash identifies a position, Zobrist style: every card in every place it could be has its own random key,
+and the hash of a position is all the keys of where its cards are, XORed together.  Moving a card takes one
+key out and puts another in, so a hash can be kept up to date as a game goes along.  The keys come from a
+fixed formula rather than a random table, so a hash is the same from one run to the next.
+
+The two copies of a card are the same card here, so swapping them doesn't change the hash.  The stock and
+the discard are in order, counted from the bottom so that drawing only takes out the key of the top card;
+the trash, hands and tableaus are sets.
+*/
+type Hash uint64
+
+// the places a card can be, for the keys
+const (
+	inStock = iota
+	inDiscard
+	inTrash
+	inHand    // then one more for the second seat, and the same for the rest
+	inStorage = inHand + 2
+	inTableau = inStorage + 2
+	inHolds   = inTableau + 2
+	inTurn    = inHolds + 2
+	inStep    = inTurn + 1 // the decisions made so far in a turn, by type
+	inWaiting = inStep + 1 // the decision waiting, then its options
+	inOption  = inWaiting + 1
+	inPaid    = inOption + 1
+)
+
+// mix is splitmix64's finisher, which spreads any small number over all 64 bits
+func mix(x uint64) Hash {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return Hash(x ^ (x >> 31))
+}
+
+// Key is the key for a card, by deck index, in a slot of a place.  For sets the slot tells the first copy
+// of a card from the second.
+func Key(place, slot, index int) Hash {
+	return mix(uint64(place)<<32 | uint64(slot)<<16 | uint64(index))
+}
+
+func turnKey(p *Position) Hash {
+	over := 0
+	if p.Over {
+		over = 1
+	}
+	return Key(inTurn, p.Turn, p.ToMove<<1|over)
+}
+
+func (h *Hash) ordered(place int, p *pile) {
+	for i := 0; i < int(p.Count); i++ {
+		*h ^= Key(place, i, int(p.Cards[i])%len(card.Deck))
+	}
+}
+
+// set hashes the cards in no order, with copies counted in the slot
+func (h *Hash) set(place int, cards []uint8, copies *[maxCards / 2]uint8) {
+	for _, c := range cards {
+		if c == noCard {
+			continue
+		}
+		index := int(c) % len(card.Deck)
+		*h ^= Key(place, int(copies[index]), index)
+		copies[index]++
+	}
+}
+
+// Hash works out the position's hash from scratch
+func (p *Position) Hash() (h Hash) {
+	h = turnKey(p)
+	h.ordered(inStock, &p.Stock)
+	h.ordered(inDiscard, &p.Discard)
+	var copies [maxCards / 2]uint8
+	h.set(inTrash, p.Trash.Cards[:p.Trash.Count], &copies)
+	for id := range p.Seats {
+		seat := &p.Seats[id]
+		copies = [maxCards / 2]uint8{}
+		h.set(inHand+id, seat.Hand[:], &copies)
+		for spot, c := range seat.Storage {
+			if c != noCard {
+				h ^= Key(inStorage+id, spot, int(c)%len(card.Deck))
+			}
+		}
+		copies = [maxCards / 2]uint8{}
+		for kind := range seat.Stacks {
+			h.set(inTableau+id, seat.Stacks[kind][:], &copies)
+		}
+		copies = [maxCards / 2]uint8{}
+		h.set(inHolds+id, seat.Holds[:seat.Held], &copies)
+	}
+	return
+}
+
+// reorder moves an ordered pile's part of the hash from one order of cards to another, slot by slot
+func (h *Hash) reorder(place int, from, to *pile) {
+	for i := 0; i < int(from.Count) || i < int(to.Count); i++ {
+		was, is := -1, -1
+		if i < int(from.Count) {
+			was = int(from.Cards[i]) % len(card.Deck)
+		}
+		if i < int(to.Count) {
+			is = int(to.Cards[i]) % len(card.Deck)
+		}
+		if was == is {
+			continue
+		}
+		if was >= 0 {
+			*h ^= Key(place, i, was)
+		}
+		if is >= 0 {
+			*h ^= Key(place, i, is)
+		}
+	}
+}
+
+// count adds up the copies of each card in a set
+func count(copies *[maxCards / 2]uint8, cards []uint8) {
+	for _, c := range cards {
+		if c != noCard {
+			copies[int(c)%len(card.Deck)]++
+		}
+	}
+}
+
+// resize moves a set's part of the hash from one count of each card to another, taking out the keys of
+// the copies that left and putting in the keys of the ones that came
+func (h *Hash) resize(place int, from, to *[maxCards / 2]uint8) {
+	for index := range from {
+		low, high := from[index], to[index]
+		if low > high {
+			low, high = high, low
+		}
+		for slot := low; slot < high; slot++ {
+			*h ^= Key(place, int(slot), index)
+		}
+	}
+}
+
+/*
+Update gives the hash of the position to, from h, the hash of the position from, with only the keys of the
+cards that moved between them taken out and put in.  It's how a search keeps its hash up to date as it goes,
+rather than working one out from scratch at every step.
+*/
+func (h Hash) Update(from, to *Position) Hash {
+	if was, is := turnKey(from), turnKey(to); was != is {
+		h ^= was ^ is
+	}
+	h.reorder(inStock, &from.Stock, &to.Stock)
+	h.reorder(inDiscard, &from.Discard, &to.Discard)
+	var was, is [maxCards / 2]uint8
+	count(&was, from.Trash.Cards[:from.Trash.Count])
+	count(&is, to.Trash.Cards[:to.Trash.Count])
+	h.resize(inTrash, &was, &is)
+	for id := range from.Seats {
+		a, b := &from.Seats[id], &to.Seats[id]
+		was, is = [maxCards / 2]uint8{}, [maxCards / 2]uint8{}
+		count(&was, a.Hand[:])
+		count(&is, b.Hand[:])
+		h.resize(inHand+id, &was, &is)
+		for spot := range a.Storage {
+			if a.Storage[spot] == b.Storage[spot] {
+				continue
+			}
+			if c := a.Storage[spot]; c != noCard {
+				h ^= Key(inStorage+id, spot, int(c)%len(card.Deck))
+			}
+			if c := b.Storage[spot]; c != noCard {
+				h ^= Key(inStorage+id, spot, int(c)%len(card.Deck))
+			}
+		}
+		was, is = [maxCards / 2]uint8{}, [maxCards / 2]uint8{}
+		for kind := range a.Stacks {
+			count(&was, a.Stacks[kind][:])
+			count(&is, b.Stacks[kind][:])
+		}
+		h.resize(inTableau+id, &was, &is)
+		was, is = [maxCards / 2]uint8{}, [maxCards / 2]uint8{}
+		count(&was, a.Holds[:a.Held])
+		count(&is, b.Holds[:b.Held])
+		h.resize(inHolds+id, &was, &is)
+	}
+	return h
+}
+
+// Hash works out the hash of the game as it stands at the start of a turn
+func (g *Game) Hash() Hash {
+	p := g.Position()
+	return p.Hash()
+}
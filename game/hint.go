@@ -0,0 +1,176 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// how many options a hint shows
+const hintCount = 3
+
+/*
+A Hint is how the computer player would rate one option of a decision.  Values are on the strategy's 0 to 63
+scale, as gains and losses against doing nothing, so they can be below zero.
+*/
+type Hint struct {
+	Option Option
+	Value  int
+	Reason string
+}
+
+/*
+Hints rates every option of the decision from the deciding seat's point of view, best first.  The rating is
+the heuristic player's, using only what the seat can see: its own cards, both tableaus, the piles, and its
+count of the cards it hasn't seen.
+*/
+func (g *Game) Hints(d Decision) (hints []Hint) {
+	// rate as the heuristic player would, with the seat's own strategy
+	advisor := g.Players[d.Seat]
+	advisor.Human = false
+	advisor.Personality = nil
+	opponent := g.Players[1-d.Seat]
+	phase := g.Clock.Phase(g.Players[0].Tableau.Fill, g.Players[1].Tableau.Fill)
+	expected, counting := advisor.ExpectedDrawValue(phase)
+	if !counting {
+		expected = player.Heuristic.DrawAt
+	}
+
+	for _, option := range d.Options {
+		h := Hint{Option: option}
+		var reasons []string
+		switch d.Type {
+		case DecideBuild:
+			if option.Pos.From == player.NoCard {
+				reasons = append(reasons, "keeps the hand")
+				break
+			}
+			built := advisor.CardByPos(option.Pos)
+			var upgrade bool
+			h.Value, _, upgrade = advisor.BuildScore(option.Pos, phase)
+			if upgrade {
+				reasons = append(reasons, "upgrade is free")
+			} else {
+				cost, _ := advisor.BuildCost(built)
+				reasons = append(reasons, fmt.Sprintf("costs %d", cost))
+			}
+			vp := built.VictoryPoints
+			if top := advisor.TopCard(built.Kind); top != nil {
+				vp -= top.VictoryPoints
+			}
+			if vp > 0 {
+				reasons = append(reasons, fmt.Sprintf("+%d VP", vp))
+			}
+			reach, chance := advisor.Threat()
+			if built.Kind == card.Defensive && chance > 0 && built.Cost > reach {
+				reasons = append(reasons, fmt.Sprintf("protects against a level-%d soldier", reach))
+			} else if chance > 0 && built.Cost <= reach {
+				reasons = append(reasons, "within reach of their soldiers")
+			}
+		case DecideDiscards:
+			var names []string
+			for _, pos := range option.Poses {
+				c := advisor.CardByPos(pos)
+				h.Value -= advisor.CardValue(c, phase)
+				names = append(names, fmt.Sprintf("%s %d", c.Name, advisor.CardValue(c, phase)))
+			}
+			reasons = append(reasons, "gives up "+strings.Join(names, ", "))
+		case DecideRedraw:
+			if !option.Yes {
+				break
+			}
+			for _, c := range advisor.Hand.Cards {
+				if c != nil {
+					h.Value += expected - advisor.CardValue(c, phase)
+				}
+			}
+			reasons = append(reasons, fmt.Sprintf("new cards are worth about %d each, but you lose the turn", expected))
+		case DecideAttack:
+			if option.Kind == -1 {
+				reasons = append(reasons, "keeps the soldier")
+				break
+			}
+			target := opponent.TopCard(option.Kind)
+			h.Value = advisor.AttackValue(target, phase)
+			if target.VictoryPoints > 0 {
+				reasons = append(reasons, fmt.Sprintf("takes %d VP off them", target.VictoryPoints))
+			}
+			reasons = append(reasons, fmt.Sprintf("worth %d to you", advisor.CardValue(target, phase)))
+		case DecideDefend:
+			if !option.Yes {
+				break
+			}
+			h.Value, reasons = g.defendHint(advisor, d, phase)
+		case DecideStore:
+			switch option.Pos.From {
+			case player.FromStock:
+				h.Value = expected
+				reasons = append(reasons, fmt.Sprintf("a card off the stock is worth about %d", expected))
+			case player.FromDiscard:
+				h.Value = advisor.CardValue(g.DiscardPile.Cards[g.DiscardPile.PullPos], phase)
+			default:
+				h.Value = advisor.CardValue(advisor.CardByPos(option.Pos), phase)
+				reasons = append(reasons, "frees a spot in the hand")
+			}
+		case DecideTrash:
+			if option.Pos.From == player.NoCard {
+				break
+			}
+			h.Value = -advisor.CardValue(advisor.CardByPos(option.Pos), phase)
+			if advisor.Tableau.DrawBonus > 0 {
+				h.Value += expected
+				reasons = append(reasons, fmt.Sprintf("draws a card worth about %d", expected))
+			} else {
+				reasons = append(reasons, "no draw to make up for it")
+			}
+		case DecideDraw:
+			if option.Yes {
+				h.Value = advisor.CardValue(d.Card, phase)
+			} else {
+				h.Value = expected
+				reasons = append(reasons, fmt.Sprintf("a card off the stock is worth about %d", expected))
+			}
+		case DecideHandLimit:
+			h.Value = -advisor.CardValue(advisor.CardByPos(option.Pos), phase)
+		}
+		h.Reason = strings.Join(reasons, ", ")
+		hints = append(hints, h)
+	}
+	sort.SliceStable(hints, func(i, j int) bool { return hints[i].Value > hints[j].Value })
+	return
+}
+
+// defendHint rates throwing a soldier in the way, against letting the attack through
+func (g *Game) defendHint(advisor player.Player, d Decision, phase int) (value int, reasons []string) {
+	defender := advisor.TopCard(card.Soldiers)
+	attackPower := d.Card.Cost + g.Players[1-d.Seat].Tableau.AttackBonus
+	target := d.Kind
+	if attackPower-defender.Cost >= advisor.TopCard(target).Cost {
+		return -advisor.CardValue(defender, phase), []string{"the attack gets through anyway"}
+	}
+	value = advisor.DefendValue(target, phase) - player.Heuristic.DefendAt
+	reasons = append(reasons, "saves your "+advisor.TopCard(target).Name)
+	if vp := advisor.TopCard(target).VictoryPoints; vp > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d VP", vp))
+	}
+	return
+}
+
+// hint shows the best few options of the decision waiting on a human
+func (g *Game) hint() string {
+	if g.pending == nil {
+		return "There's nothing to decide\n"
+	}
+	hints := g.Hints(*g.pending)
+	output := "-=* HINT *=-\n"
+	for i, h := range hints {
+		if i == hintCount {
+			break
+		}
+		output += fmt.Sprintf("%-40s %4d  %s\n", h.Option.Label, h.Value, h.Reason)
+	}
+	return output
+}
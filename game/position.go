@@ -0,0 +1,218 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// the most cards a game can have, two copies of each card in the deck
+const maxCards = 80
+
+// noCard marks an empty spot in a Position
+const noCard = 0xFF
+
+/*
+A Position is the state of a game at the start of a turn, like State, but small and with nothing to point
+at, so copying one is all it takes to clone it.  A card is a byte: its index into the game's cards, where
+the first copy of each card in card.Deck comes first and then the second.  Spots that keep their place,
+like the slots of a hand, use noCard for an empty one.
+*/
+type Position struct {
+	Turn    int
+	ToMove  int
+	Over    bool
+	Ending  int
+	Stock   pile
+	Discard pile
+	Trash   pile
+	Seats   [2]seatPosition
+}
+
+// a pile is listed bottom first, like State
+type pile struct {
+	Cards [maxCards]uint8
+	Count uint8
+}
+
+type seatPosition struct {
+	Hand    [7]uint8
+	Storage [2]uint8
+	Stacks  [10][5]uint8 // by kind, each card in the spot for its cost
+	Holds   [7]uint8     // the cards this seat saw go into the opponent's hand, and are still there
+	Held    uint8        // how many of Holds there are
+}
+
+func init() {
+	if 2*len(card.Deck) > maxCards {
+		panic(fmt.Sprintf("a Position has room for %d cards, the deck has %d", maxCards, 2*len(card.Deck)))
+	}
+}
+
+// Clone gives a copy of the position.  It's the same as assigning it.
+func (p Position) Clone() Position {
+	return p
+}
+
+// the byte for a card, noCard for nil
+func (g *Game) cardID(c *card.Card) uint8 {
+	if c == nil {
+		return noCard
+	}
+	id, ok := g.ids[c]
+	if !ok {
+		panic(fmt.Sprintf("%s isn't one of this game's cards", c.Name))
+	}
+	return id
+}
+
+func (g *Game) cardAt(id uint8) *card.Card {
+	if id == noCard {
+		return nil
+	}
+	return &g.cards[id]
+}
+
+func (g *Game) takePile(p *pile, from *card.Hand) {
+	p.Count = uint8(from.PullPos + 1)
+	for i := 0; i < int(p.Count); i++ {
+		p.Cards[i] = g.cardID(from.Cards[i])
+	}
+}
+
+func (g *Game) putPile(p *pile, to *card.Hand) {
+	for i := range to.Cards {
+		to.Cards[i] = nil
+		if i < int(p.Count) {
+			to.Cards[i] = g.cardAt(p.Cards[i])
+		}
+	}
+	to.PullPos = int(p.Count) - 1
+}
+
+// Position takes a snapshot of the game at the start of a turn
+func (g *Game) Position() (p Position) {
+	p.Turn, p.ToMove, p.Over, p.Ending = g.Turn, g.ToMove, g.Over, g.Ending
+	g.takePile(&p.Stock, &g.Stock)
+	g.takePile(&p.Discard, &g.DiscardPile)
+	g.takePile(&p.Trash, &g.Trash)
+	for id, pl := range g.Players {
+		seat := &p.Seats[id]
+		for i := range seat.Hand {
+			seat.Hand[i] = noCard
+			if i < len(pl.Hand.Cards) {
+				seat.Hand[i] = g.cardID(pl.Hand.Cards[i])
+			}
+		}
+		for spot := range seat.Storage {
+			seat.Storage[spot] = g.cardID(pl.Tableau.Storage[spot])
+		}
+		for kind := range seat.Stacks {
+			stack := pl.Tableau.Stack[kind]
+			for cost := range seat.Stacks[kind] {
+				seat.Stacks[kind][cost] = noCard
+				if stack != nil {
+					seat.Stacks[kind][cost] = g.cardID(stack.Cards[cost])
+				}
+			}
+		}
+		// the cards remembered in the opponent's hand are the ones there now, as in Restore
+		if pl.Tracker == nil {
+			continue
+		}
+		for _, c := range pl.Tracker.OpponentHolds {
+			for _, held := range g.Players[1-id].Hand.Cards {
+				if held == c && int(seat.Held) < len(seat.Holds) {
+					seat.Holds[seat.Held] = g.cardID(c)
+					seat.Held++
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+/*
+SetPosition puts the game in the position, in place, which is much quicker than setting up a new game.
+The game log is cleared, and any Choosers and Overseers are kept.  The position should come from a game
+with the same rules, since it isn't checked.
+*/
+func (g *Game) SetPosition(p Position) {
+	g.Turn, g.ToMove, g.Over, g.Ending = p.Turn, p.ToMove, p.Over, p.Ending
+	g.Clock.Turn = p.Turn
+	g.Forfeited = -1
+	g.Events = g.Events[:0]
+	g.pending = nil
+	g.putPile(&p.Stock, &g.Stock)
+	g.putPile(&p.Discard, &g.DiscardPile)
+	g.putPile(&p.Trash, &g.Trash)
+	for id, pl := range g.Players {
+		seat := &p.Seats[id]
+		pl.Hand.Count = 0
+		for i := range pl.Hand.Cards {
+			pl.Hand.Cards[i] = nil
+			if i < len(seat.Hand) && seat.Hand[i] != noCard {
+				pl.Hand.Cards[i] = g.cardAt(seat.Hand[i])
+				pl.Hand.Count++
+			}
+		}
+		for spot := range seat.Storage {
+			pl.Tableau.Storage[spot] = g.cardAt(seat.Storage[spot])
+		}
+		for kind := range seat.Stacks {
+			var stack *card.Hand
+			for cost, id := range seat.Stacks[kind] {
+				if id == noCard {
+					continue
+				}
+				if stack == nil {
+					// keep the stack the player had, if there was one
+					if stack = pl.Tableau.Stack[kind]; stack == nil {
+						stack = &card.Hand{Cards: make([]*card.Card, 5)}
+					}
+					for i := range stack.Cards {
+						stack.Cards[i] = nil
+					}
+				}
+				stack.Cards[cost] = g.cardAt(id)
+				stack.PullPos = cost
+			}
+			if stack == nil {
+				delete(pl.Tableau.Stack, kind)
+			} else {
+				pl.Tableau.Stack[kind] = stack
+			}
+		}
+		pl.Tableau.Recount()
+		pl.Tracker.Reset()
+	}
+	for id := range g.Players {
+		seat := &p.Seats[id]
+		for i := 0; i < int(seat.Held); i++ {
+			g.Players[id].Tracker.OpponentTook(g.cardAt(seat.Holds[i]))
+		}
+	}
+	g.out = len(g.cards) - p.count()
+	if g.options.Record {
+		g.RecordFromHere()
+	}
+}
+
+// count gives how many cards are in the position, which is all of the game's but those out of the game
+func (p *Position) count() (n int) {
+	n = int(p.Stock.Count) + int(p.Discard.Count) + int(p.Trash.Count)
+	for id := range p.Seats {
+		seat := &p.Seats[id]
+		spots := append(seat.Hand[:], seat.Storage[:]...)
+		for kind := range seat.Stacks {
+			spots = append(spots, seat.Stacks[kind][:]...)
+		}
+		for _, c := range spots {
+			if c != noCard {
+				n++
+			}
+		}
+	}
+	return
+}
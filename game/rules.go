@@ -0,0 +1,135 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// Where used cards can end up
+const ToTrash = 0
+const ToDiscard = 1
+
+// How storage buildings fill their spots
+const StorageRefill = 0   // every storage build fills any open spot it covers
+const StorageCardText = 1 // follow the card text: Shed and Storehouse only fill their own new spot
+
+/*
+Rules hold the parts of the rules that are still open questions, so different readings can be played
+against each other.  DefaultRules is how the game has always been played here.
+*/
+type Rules struct {
+	StorageFill      int  // StorageRefill or StorageCardText
+	SpendStorage     bool // cards in storage may be discarded to pay for a build, or trashed
+	HandLimitTo      int  // where cards go when you discard down to the hand limit, ToTrash or ToDiscard
+	SoldiersTo       int  // where a soldier goes after it attacks, ToTrash or ToDiscard
+	RedrawOnFullHand bool // a player who can't build with a full hand must dump it and redraw
+	TurnCap          int  // the game ends after this many turns as a safety, 0 for no cap
+	SoldiersDefend   bool // a player may throw their own soldier in the way of an attack, taking its value off
+	RedrawToTrash    bool // a redrawn hand goes into the trash, rather than out of the game
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		StorageFill:      StorageRefill,
+		SpendStorage:     true,
+		HandLimitTo:      ToTrash,
+		SoldiersTo:       ToTrash,
+		RedrawOnFullHand: true,
+		TurnCap:          30,
+	}
+}
+
+var pileNames = map[string]int{"trash": ToTrash, "discard": ToDiscard}
+var storageNames = map[string]int{"refill": StorageRefill, "cardtext": StorageCardText}
+
+func nameOf(names map[string]int, value int) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(value)
+}
+
+/*
+ParseRules starts from the default rules and changes the settings given as a comma separated list:
+
+	storage=refill|cardtext
+	spendstorage=true|false
+	handlimit=trash|discard
+	soldiers=trash|discard
+	redraw=true|false
+	turncap=30
+	defend=true|false
+	redrawtrash=true|false
+
+An empty string is the default rules.
+*/
+func ParseRules(spec string) (rules Rules, err error) {
+	rules = DefaultRules()
+	for _, setting := range strings.Split(spec, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+		key, value, found := strings.Cut(setting, "=")
+		if !found {
+			return rules, fmt.Errorf("rule %q should look like name=value", setting)
+		}
+		var ok bool
+		switch strings.ToLower(key) {
+		case "storage":
+			rules.StorageFill, ok = storageNames[value]
+		case "spendstorage":
+			rules.SpendStorage, err = strconv.ParseBool(value)
+			ok = err == nil
+		case "handlimit":
+			rules.HandLimitTo, ok = pileNames[value]
+		case "soldiers":
+			rules.SoldiersTo, ok = pileNames[value]
+		case "redraw":
+			rules.RedrawOnFullHand, err = strconv.ParseBool(value)
+			ok = err == nil
+		case "defend":
+			rules.SoldiersDefend, err = strconv.ParseBool(value)
+			ok = err == nil
+		case "redrawtrash":
+			rules.RedrawToTrash, err = strconv.ParseBool(value)
+			ok = err == nil
+		case "turncap":
+			rules.TurnCap, err = strconv.Atoi(value)
+			ok = err == nil && rules.TurnCap >= 0
+		default:
+			return rules, fmt.Errorf("unknown rule %q", key)
+		}
+		if !ok {
+			return rules, fmt.Errorf("bad value for rule %q: %q", key, value)
+		}
+	}
+	return rules, nil
+}
+
+func (r Rules) String() string {
+	return fmt.Sprintf("storage=%s,spendstorage=%t,handlimit=%s,soldiers=%s,redraw=%t,turncap=%d,defend=%t,redrawtrash=%t",
+		nameOf(storageNames, r.StorageFill), r.SpendStorage, nameOf(pileNames, r.HandLimitTo),
+		nameOf(pileNames, r.SoldiersTo), r.RedrawOnFullHand, r.TurnCap, r.SoldiersDefend, r.RedrawToTrash)
+}
+
+// the pile used cards go to under these rules
+func (g *Game) pile(to int) *card.Hand {
+	if to == ToDiscard {
+		return &g.DiscardPile
+	}
+	return &g.Trash
+}
+
+// where a redrawn hand goes under these rules, nil for out of the game
+func (g *Game) redrawPile() *card.Hand {
+	if g.Rules.RedrawToTrash {
+		return &g.Trash
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A State is everything needed to pick a game up again at the start of a turn, with cards given by their
+index in card.Deck.  Piles are listed bottom first, so the last card of Stock is the next one drawn.
+The game log isn't kept.
+*/
+type State struct {
+	Seed        int64
+	Rules       Rules
+	Turn        int
+	ToMove      int
+	Over        bool
+	Ending      int `json:",omitempty"`
+	Stock       []int
+	DiscardPile []int
+	Trash       []int
+	Seats       [2]SeatState
+}
+
+// SeatState is one player's side of the table
+type SeatState struct {
+	Name          string `json:",omitempty"`
+	Hand          []int
+	Storage       [2]int           // -1 for an empty spot
+	Tableau       map[string][]int // by kind name, each stack bottom first
+	OpponentHolds []int            `json:",omitempty"` // the cards this player saw go into the opponent's hand
+}
+
+func indexes(cards []*card.Card) (list []int) {
+	list = []int{}
+	for _, c := range cards {
+		if c != nil {
+			list = append(list, card.DeckIndex(c))
+		}
+	}
+	return
+}
+
+// State takes a snapshot of the game
+func (g *Game) State() State {
+	s := State{
+		Seed:        g.options.Seed,
+		Rules:       g.Rules,
+		Turn:        g.Turn,
+		ToMove:      g.ToMove,
+		Over:        g.Over,
+		Ending:      g.Ending,
+		Stock:       indexes(g.Stock.Cards[:g.Stock.PullPos+1]),
+		DiscardPile: indexes(g.DiscardPile.Cards[:g.DiscardPile.PullPos+1]),
+		Trash:       indexes(g.Trash.Cards[:g.Trash.PullPos+1]),
+	}
+	for id, p := range g.Players {
+		seat := SeatState{Hand: indexes(p.Hand.Cards), Tableau: make(map[string][]int)}
+		if id < len(g.options.Seats) {
+			seat.Name = g.options.Seats[id].Name
+		}
+		for spot, c := range p.Tableau.Storage {
+			seat.Storage[spot] = -1
+			if c != nil {
+				seat.Storage[spot] = card.DeckIndex(c)
+			}
+		}
+		for kind, stack := range p.Tableau.Stack {
+			if stack != nil {
+				seat.Tableau[card.KindName(kind)] = indexes(stack.Cards)
+			}
+		}
+		if p.Tracker != nil && len(p.Tracker.OpponentHolds) > 0 {
+			seat.OpponentHolds = indexes(p.Tracker.OpponentHolds)
+		}
+		s.Seats[id] = seat
+	}
+	return s
+}
+
+/*
+Restore sets up a game from a snapshot, with the given seats playing.  There are two copies of each card,
+and Restore complains if a state asks for more.
+*/
+func Restore(s State, seats []Seat) (*Game, error) {
+	p, err := s.Position()
+	if err != nil {
+		return nil, err
+	}
+	rules := s.Rules
+	g := New(Options{Seed: s.Seed, Seats: seats, Rules: &rules})
+	g.SetPosition(p)
+	return g, nil
+}
+
+// Position packs the snapshot into a Position, checking it's a legal one
+func (s State) Position() (p Position, err error) {
+	p.Turn, p.ToMove, p.Over, p.Ending = s.Turn, s.ToMove, s.Over, s.Ending
+
+	// hand out the copies of each card, the first and then the second
+	used := make([]int, len(card.Deck))
+	take := func(index int) (uint8, error) {
+		if index < 0 || index >= len(card.Deck) {
+			return noCard, fmt.Errorf("there's no card %d in the deck", index)
+		}
+		if used[index] == 2 {
+			return noCard, fmt.Errorf("more than two copies of %s", card.Deck[index].Name)
+		}
+		used[index]++
+		return uint8(index + (used[index]-1)*len(card.Deck)), nil
+	}
+	fill := func(to *pile, list []int) (err error) {
+		for i, index := range list {
+			if to.Cards[i], err = take(index); err != nil {
+				return
+			}
+		}
+		to.Count = uint8(len(list))
+		return
+	}
+	if err = fill(&p.Stock, s.Stock); err != nil {
+		return
+	}
+	if err = fill(&p.Discard, s.DiscardPile); err != nil {
+		return
+	}
+	if err = fill(&p.Trash, s.Trash); err != nil {
+		return
+	}
+
+	for id, seat := range s.Seats {
+		to := &p.Seats[id]
+		if len(seat.Hand) > len(to.Hand) {
+			return p, fmt.Errorf("player %d holds more than %d cards", id, len(to.Hand))
+		}
+		for i := range to.Hand {
+			to.Hand[i] = noCard
+			if i < len(seat.Hand) {
+				if to.Hand[i], err = take(seat.Hand[i]); err != nil {
+					return
+				}
+			}
+		}
+		for spot, index := range seat.Storage {
+			to.Storage[spot] = noCard
+			if index >= 0 {
+				if to.Storage[spot], err = take(index); err != nil {
+					return
+				}
+			}
+		}
+		for kind := range to.Stacks {
+			for cost := range to.Stacks[kind] {
+				to.Stacks[kind][cost] = noCard
+			}
+		}
+		for name, list := range seat.Tableau {
+			kind := card.KindByName(name)
+			if kind == -1 {
+				return p, fmt.Errorf("there's no card kind %q", name)
+			}
+			for _, index := range list {
+				c, err := take(index)
+				if err != nil {
+					return p, err
+				}
+				if card.Deck[index].Kind != kind {
+					return p, fmt.Errorf("%s isn't a %s", card.Deck[index].Name, name)
+				}
+				// a stack keeps each card in the spot for its cost, like Build does
+				to.Stacks[kind][card.Deck[index].Cost] = c
+			}
+		}
+	}
+	// the cards remembered in the opponent's hand are the ones there now
+	for id, seat := range s.Seats {
+		to := &p.Seats[id]
+		for _, index := range seat.OpponentHolds {
+			for _, c := range p.Seats[1-id].Hand {
+				if c != noCard && int(c)%len(card.Deck) == index && int(to.Held) < len(to.Holds) {
+					to.Holds[to.Held] = c
+					to.Held++
+					break
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// RecordFromHere starts keeping every decision in the game log, with the game as it stands as the start
+func (g *Game) RecordFromHere() {
+	g.options.Record = true
+	start := g.State()
+	g.Start = &start
+}
+
+// Clone makes a separate copy of the game as it stands at the start of a turn, with the same seats.
+// The game log and any Choosers set on the game itself, or Overseers, are left behind.
+func (g *Game) Clone() *Game {
+	rules := g.Rules
+	c := New(Options{Seed: g.options.Seed, Seats: g.options.Seats, Rules: &rules})
+	c.SetPosition(g.Position())
+	return c
+}
+
+// Save writes the state of the game to a file as JSON
+func (g *Game) Save(path string) error {
+	data, err := json.MarshalIndent(g.State(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load picks up a saved game, with the given seats playing
+func Load(path string, seats []Seat) (*Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return Restore(s, seats)
+}
@@ -0,0 +1,221 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A Scenario sets a game up a particular way, to try out a rule or a situation without waiting for the shuffle
+to come up with it.  Cards are given by name.  The stock is listed top first, in the order it's drawn, and
+the rest of the deck is shuffled in underneath it.  The discard and the trash are listed bottom first, like
+a State.  A seat with no hand given is dealt one from the stock as usual, so stacking the stock is enough
+to pick the opening hands, player 0's first.
+
+	{
+		"name": "quick-knights",
+		"description": "both hands are set, and Knights are the first card drawn",
+		"stock": ["Knights"],
+		"seats": [
+			{"hand": ["Fort", "Fowlery", "Pig Farm", "Cow fields", "Manor"]},
+			{"hand": ["Trading Post", "Bazaar", "Exchange", "Faire", "Shed"], "tableau": ["Walls"]}
+		]
+	}
+*/
+type Scenario struct {
+	Name        string
+	Description string
+	Stock       []string `json:",omitempty"`
+	Discard     []string `json:",omitempty"`
+	Trash       []string `json:",omitempty"`
+	Seats       [2]SeatScenario
+}
+
+// SeatScenario is how one player's side of the table starts
+type SeatScenario struct {
+	Hand    []string // leave it out to be dealt a hand, or give an empty list for none
+	Storage []string `json:",omitempty"` // up to two, the first goes in the first spot
+	Tableau []string `json:",omitempty"` // the buildings and soldiers already built, in any order
+}
+
+// a scenario with its cards worked out, as indexes into a game's cards like a Position
+type layout struct {
+	stock, discard, trash []uint8 // the stock top first
+	seats                 [2]struct {
+		dealt         bool
+		hand, storage []uint8
+		tableau       []uint8
+	}
+	laid [maxCards]bool // the cards placed, which aren't shuffled into the stock
+}
+
+// lay finds the cards of the scenario, checking there are no more than two of each and that they fit
+func (s *Scenario) lay() (l *layout, err error) {
+	l = &layout{}
+	used := make([]int, len(card.Deck))
+	take := func(names []string) (ids []uint8, err error) {
+		for _, name := range names {
+			index := card.IndexByName(name)
+			if index == -1 {
+				return nil, fmt.Errorf("there's no card called %q", name)
+			}
+			if used[index] == 2 {
+				return nil, fmt.Errorf("more than two copies of %s", card.Deck[index].Name)
+			}
+			id := index + used[index]*len(card.Deck)
+			used[index]++
+			l.laid[id] = true
+			ids = append(ids, uint8(id))
+		}
+		return
+	}
+	if l.stock, err = take(s.Stock); err != nil {
+		return nil, err
+	}
+	if l.discard, err = take(s.Discard); err != nil {
+		return nil, err
+	}
+	if l.trash, err = take(s.Trash); err != nil {
+		return nil, err
+	}
+	for id, seat := range s.Seats {
+		to := &l.seats[id]
+		to.dealt = seat.Hand == nil
+		if len(seat.Hand) > 7 {
+			return nil, fmt.Errorf("player %d holds more than 7 cards", id)
+		}
+		if len(seat.Storage) > 2 {
+			return nil, fmt.Errorf("player %d has more than 2 cards in storage", id)
+		}
+		if to.hand, err = take(seat.Hand); err != nil {
+			return nil, err
+		}
+		if to.storage, err = take(seat.Storage); err != nil {
+			return nil, err
+		}
+		if to.tableau, err = take(seat.Tableau); err != nil {
+			return nil, err
+		}
+		built := make(map[int]string)
+		for _, c := range to.tableau {
+			d := card.Deck[int(c)%len(card.Deck)]
+			spot := d.Kind*10 + d.Cost
+			if built[spot] != "" {
+				return nil, fmt.Errorf("player %d has built %s twice", id, d.Name)
+			}
+			built[spot] = d.Name
+		}
+	}
+	return l, nil
+}
+
+// Check makes sure the scenario can be set up
+func (s *Scenario) Check() error {
+	_, err := s.lay()
+	if err != nil && s.Name != "" {
+		return fmt.Errorf("scenario %s: %v", s.Name, err)
+	}
+	return err
+}
+
+// ParseScenario reads a scenario from JSON and checks it
+func ParseScenario(data []byte) (*Scenario, error) {
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.Check(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadScenario reads a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if s.Name == "" {
+		s.Name = path
+	}
+	return s, nil
+}
+
+// setUp puts out the cards of the scenario that aren't in the stock, once the other hands are dealt
+func (g *Game) setUp(l *layout) {
+	for _, id := range l.discard {
+		g.DiscardPile.Place(g.cardAt(id))
+	}
+	for _, id := range l.trash {
+		g.Trash.Place(g.cardAt(id))
+	}
+	for seat, from := range l.seats {
+		p := g.Players[seat]
+		for i, id := range from.hand {
+			p.Hand.Cards[i] = g.cardAt(id)
+			p.Hand.Count++
+		}
+		for spot, id := range from.storage {
+			p.Tableau.Storage[spot] = g.cardAt(id)
+		}
+		for _, id := range from.tableau {
+			c := g.cardAt(id)
+			stack := p.Tableau.Stack[c.Kind]
+			if stack == nil {
+				stack = &card.Hand{Cards: make([]*card.Card, 5), PullPos: -1}
+				p.Tableau.Stack[c.Kind] = stack
+			}
+			// a stack keeps each card in the spot for its cost, like Build does
+			stack.Cards[c.Cost] = c
+			if c.Cost > stack.PullPos {
+				stack.PullPos = c.Cost
+			}
+		}
+		p.Tableau.Recount()
+	}
+}
+
+func cardNameList(cards []*card.Card) []string {
+	names := []string{}
+	for _, c := range cards {
+		if c != nil {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+/*
+Scenario gives a scenario that deals every card where it is in this game, so a new game set up from it is
+the same as this one, whatever the seed.  Only the cards are kept, so it's for a game that hasn't started.
+*/
+func (g *Game) Scenario(name, description string) *Scenario {
+	s := &Scenario{
+		Name:        name,
+		Description: description,
+		Discard:     cardNameList(g.DiscardPile.Cards[:g.DiscardPile.PullPos+1]),
+		Trash:       cardNameList(g.Trash.Cards[:g.Trash.PullPos+1]),
+	}
+	for i := g.Stock.PullPos; i >= 0; i-- {
+		s.Stock = append(s.Stock, g.Stock.Cards[i].Name)
+	}
+	for id, p := range g.Players {
+		seat := &s.Seats[id]
+		seat.Hand = cardNameList(p.Hand.Cards)
+		seat.Storage = cardNameList(p.Tableau.Storage)
+		for kind := 0; kind <= 9; kind++ {
+			if stack := p.Tableau.Stack[kind]; stack != nil {
+				seat.Tableau = append(seat.Tableau, cardNameList(stack.Cards)...)
+			}
+		}
+	}
+	return s
+}
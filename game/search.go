@@ -0,0 +1,232 @@
+package game
+
+import (
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// A Choice is a decision along a Searcher's line of play, and the option taken
+type Choice struct {
+	Turn     int
+	Decision Decision
+	Option   int
+}
+
+func (c Choice) String() string {
+	return c.Decision.Options[c.Option].Label
+}
+
+// a node of the search: the start of its turn, the choices made since then to reach it, and the cards
+// where they stood when it got there
+type node struct {
+	start    Position
+	choices  []int
+	at       Position
+	turn     int
+	decision *Decision                  // waiting here, nil if the game is over
+	made     [DecideHandLimit + 1]uint8 // how many of each type of decision have been made this turn
+	cards    Hash                       // where the cards are, at
+	steps    Hash                       // a key for each decision made this turn, by type and count
+	hash     Hash
+}
+
+// step counts a decision made this turn into the node's steps
+func (n *node) step(decision int) {
+	n.steps ^= Key(inStep, int(n.made[decision]), decision)
+	n.made[decision]++
+}
+
+// waitingKey is the key for a decision: its type, what it's about, and its options in order
+func waitingKey(d *Decision) Hash {
+	about := len(card.Deck)
+	if d.Card != nil {
+		about = card.DeckIndex(d.Card)
+	}
+	h := Key(inWaiting, len(d.Options), d.Type<<12|about<<4|(d.Kind+1))
+	place := func(pos player.Pos) int {
+		return pos.From<<3 | pos.Index
+	}
+	for i, o := range d.Options {
+		yes := 0
+		if o.Yes {
+			yes = 1
+		}
+		h ^= Key(inOption, i, place(o.Pos)|(o.Kind+1)<<5|yes<<9)
+		for _, pos := range o.Poses {
+			h ^= Key(inPaid, i, place(pos))
+		}
+	}
+	return h
+}
+
+/*
+A Searcher walks the tree of decisions from a position, for players that search ahead.  Apply takes an
+option of the decision waiting and Undo takes it back.
+
+The engine can only stop at a decision partway through a turn by being asked one, so each Apply plays the
+turn again from its start, in a scratch game set to the position at the start of the turn, and stops it with
+a panic at the next decision.  That makes an Apply cost as much as the turn so far, which grows with every
+decision in it, though a turn rarely has more than a handful.  Each node keeps the cards as they stood when
+it got there, so Undo is only a pop, and the game at the decision before is set back in place when it's asked
+for without playing anything.
+
+So Apply isn't the cheap apply that a value-type position was meant to give search.  A Position clones in
+tens of nanoseconds, but an Apply is a replay of the turn, which runs to hundreds of times that (see the
+benchmarks in bench_test.go).  A real incremental apply would need the engine to be able to stop partway
+through a turn, and pick up again from a decision, which it can't yet.
+
+The search sees everything, the stock and both hands, so it's for looking ahead with perfect information,
+or for trying out a guess at the hidden cards made with Determinize.
+*/
+type Searcher struct {
+	g     *Game
+	path  []node
+	line  []Choice
+	fresh bool // the scratch game is at the end of the path
+}
+
+// stop is thrown to end a replay at the first decision past the end of the script.  It names the script,
+// so a replay only catches its own.
+type stop struct{ script *script }
+
+// script plays a list of choices into the engine, then stops the game at the next decision
+type script struct {
+	choices []int
+	made    int
+	types   []int // the type of each decision the choices were made for
+	next    *Decision
+}
+
+func (s *script) Choose(view *player.PlayerView, d Decision) int {
+	if s.made == len(s.choices) {
+		s.next = &d
+		panic(stop{s})
+	}
+	s.made++
+	s.types = append(s.types, d.Type)
+	return s.choices[s.made-1]
+}
+
+// NewSearcher starts a search from the game as it stands at the start of a turn, with the given seats
+// making any decisions the search doesn't.  The game itself isn't changed.
+func NewSearcher(g *Game, seats []Seat) *Searcher {
+	rules := g.Rules
+	s := &Searcher{g: New(Options{Seed: g.options.Seed, Seats: seats, Rules: &rules})}
+	s.path = []node{s.play(g.Position(), nil, nil)}
+	s.fresh = true
+	return s
+}
+
+/*
+play runs the choices from the start of a turn, and gives back where they lead.  The hash is worked out from
+the parent's, the node the last choice was made at, by the cards that moved since and that one more choice.
+If the choices run into another turn, the count of decisions starts again from there.  The root has no
+parent, so its hash is worked out from scratch.
+*/
+func (s *Searcher) play(start Position, choices []int, parent *node) (n node) {
+	g := s.g
+	g.SetPosition(start)
+	sc := &script{choices: choices}
+	g.Choosers = [2]Chooser{sc, sc}
+	n = node{start: start, choices: choices}
+	crossed, from := false, 0
+	defer func() {
+		if r := recover(); r != nil {
+			if halt, ok := r.(stop); !ok || halt.script != sc {
+				panic(r)
+			}
+			n.decision = sc.next
+		}
+		n.turn, n.at = g.Turn, g.Position()
+		if parent == nil {
+			n.cards = n.at.Hash()
+		} else {
+			n.cards = parent.cards.Update(&parent.at, &n.at)
+		}
+		if parent != nil && !crossed {
+			n.made, n.steps = parent.made, parent.steps
+			n.step(sc.types[len(sc.types)-1])
+		} else {
+			for _, decision := range sc.types[from:] {
+				n.step(decision)
+			}
+		}
+		n.hash = n.cards ^ n.steps
+		if n.decision != nil {
+			n.hash ^= waitingKey(n.decision)
+		}
+	}()
+	for !g.Over {
+		g.PlayTurn()
+		// a new turn, so start from here
+		n.start, n.choices = g.Position(), choices[sc.made:]
+		crossed, from = true, sc.made
+	}
+	return
+}
+
+// Decision is the decision waiting to be made, or nil if the game is over
+func (s *Searcher) Decision() *Decision {
+	return s.path[len(s.path)-1].decision
+}
+
+// Apply takes an option of the decision waiting
+func (s *Searcher) Apply(option int) {
+	at := s.path[len(s.path)-1]
+	if at.decision == nil {
+		panic("the game is over, there's nothing to decide")
+	}
+	choices := make([]int, len(at.choices)+1)
+	copy(choices, at.choices)
+	choices[len(at.choices)] = option
+	s.line = append(s.line, Choice{Turn: at.turn, Decision: *at.decision, Option: option})
+	s.path = append(s.path, s.play(at.start, choices, &at))
+	s.fresh = true
+}
+
+// Undo takes back the last option applied
+func (s *Searcher) Undo() {
+	if len(s.line) == 0 {
+		panic("nothing to undo")
+	}
+	s.path = s.path[:len(s.path)-1]
+	s.line = s.line[:len(s.line)-1]
+	s.fresh = false
+}
+
+// Line gives the options applied so far.  Don't change it, it's shared.
+func (s *Searcher) Line() []Choice {
+	return s.line
+}
+
+// Game gives the game as it is at the decision waiting, or at the end.  Partway through a turn after an
+// Undo it only has the cards in place, the engine isn't stopped inside the turn.  Don't change it.
+func (s *Searcher) Game() *Game {
+	if !s.fresh {
+		s.g.SetPosition(s.path[len(s.path)-1].at)
+		s.fresh = true
+	}
+	return s.g
+}
+
+/*
+Hash identifies where the search is.  It's made of the keys of where the cards are, kept up to date by each
+Apply taking out and putting in the keys of the cards it moved, and a key for the decision waiting and its
+options.  Partway through a turn the engine is somewhere in the middle of its rules, which the cards don't
+show, so there's also a key for each decision made since the start of the turn, by type and how many of
+that type came before.  None of it depends on the order things were done in, so building one card and then
+another comes to the same hash as building them the other way around.
+*/
+func (s *Searcher) Hash() Hash {
+	return s.path[len(s.path)-1].hash
+}
+
+// TurnStart is true when the decision waiting is the first of its turn, or the game is over
+func (s *Searcher) TurnStart() bool {
+	return len(s.path[len(s.path)-1].choices) == 0
+}
+
+// VictoryPoints is the score at the decision waiting, or at the end
+func (s *Searcher) VictoryPoints() []int {
+	return s.Game().VictoryPoints()
+}
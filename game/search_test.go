@@ -0,0 +1,80 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/chrislunt/warwick/player"
+)
+
+// the option of the decision waiting that builds the named card
+func buildOption(t *testing.T, s *Searcher, name string) int {
+	t.Helper()
+	d := s.Decision()
+	if d == nil || d.Type != DecideBuild {
+		t.Fatalf("waiting for %v, not a build", d)
+	}
+	g := s.Game()
+	for i, o := range d.Options {
+		if o.Pos.From != player.NoCard && g.Players[d.Seat].CardByPos(o.Pos).Name == name {
+			return i
+		}
+	}
+	t.Fatalf("no option to build %s", name)
+	return -1
+}
+
+// TestSearcherTransposes builds two upgrades in one turn, one way around and then the other, and the
+// hash has to come out the same
+func TestSearcherTransposes(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	g := New(Options{Seed: 1, Scenario: &Scenario{
+		Discard: []string{"Castle"},
+		Seats: [2]SeatScenario{
+			{Hand: []string{"Pig Farm", "Tower", "Chapel", "Sawmill", "Armory"}, Tableau: []string{"Novice", "Fowlery", "Walls", "Trading Post"}},
+			{Hand: []string{"Shed", "Mine", "Garrison", "Church", "Keep"}},
+		},
+	}})
+	s := NewSearcher(g, nil)
+	build := func(first, second string) (Hash, int) {
+		s.Apply(buildOption(t, s, first))
+		s.Apply(buildOption(t, s, second))
+		if s.TurnStart() {
+			t.Fatalf("the turn ended after building %s and %s", first, second)
+		}
+		hash, decision := s.Hash(), s.Decision().Type
+		s.Undo()
+		s.Undo()
+		return hash, decision
+	}
+	start := s.Hash()
+	one, after := build("Pig Farm", "Tower")
+	other, _ := build("Tower", "Pig Farm")
+	if one != other {
+		t.Errorf("building Pig Farm then Tower hashes to %x, the other way around to %x", one, other)
+	}
+	if one == start {
+		t.Errorf("the hash didn't change over two builds")
+	}
+	if s.Hash() != start {
+		t.Errorf("undoing both builds left the hash at %x, it started at %x", s.Hash(), start)
+	}
+	if after == DecideBuild {
+		t.Errorf("still waiting to build after both builds")
+	}
+}
+
+// TestHashUpdate plays a few hundred decisions down a line, checking the hash kept up to date by the
+// cards that moved is the one worked out from scratch
+func TestHashUpdate(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	for seed := int64(1); seed <= 5; seed++ {
+		s := NewSearcher(New(Options{Seed: seed}), nil)
+		for steps := 0; steps < 300 && s.Decision() != nil; steps++ {
+			s.Apply(steps % len(s.Decision().Options))
+			at := s.path[len(s.path)-1]
+			if at.cards != at.at.Hash() {
+				t.Fatalf("seed %d, after %d steps: the hash is %x, from scratch it's %x", seed, steps+1, at.cards, at.at.Hash())
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrislunt/warwick/player"
+)
+
+// A Seat says who is playing one side of the table
+type Seat struct {
+	Name        string
+	Human       bool
+	Strategy    [][][]int           // nil for the default strategy
+	Personality *player.Personality // nil for the original heuristic
+	Chooser     Chooser             // makes the seat's decisions in place of the player, like a bot
+	UI          player.UI           // where a human plays, nil for a console on the terminal
+}
+
+/*
+ParseSeat reads a seat description as given on the command line:
+
+	human                  a person at the console
+	heuristic              the computer player with the default strategy
+	heuristic:file.json    the computer player with a strategy file
+	file.json              short for heuristic:file.json
+	warlord                a computer player with a personality, see player.Personalities
+	warlord:file.json      ... and a strategy file
+*/
+func ParseSeat(spec string) (seat Seat, err error) {
+	agent, file, _ := strings.Cut(spec, ":")
+	if strings.HasSuffix(agent, ".json") {
+		agent, file = "heuristic", spec
+	}
+	seat.Name = spec
+	if agent == "human" {
+		seat.Human = true
+	} else if personality, ok := player.Personalities[agent]; ok {
+		seat.Personality = &personality
+	} else {
+		return seat, fmt.Errorf("unknown agent %q, use human, heuristic, warlord, builder, turtle or merchant, with an optional :strategy.json", agent)
+	}
+	if file != "" {
+		var name string
+		name, seat.Strategy, err = player.LoadStrategy(file)
+		if err != nil {
+			return
+		}
+		if agent == "heuristic" {
+			seat.Name = name
+		} else {
+			seat.Name = agent + ":" + name
+		}
+	}
+	return
+}
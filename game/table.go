@@ -0,0 +1,84 @@
+package game
+
+import (
+	"sync"
+)
+
+// How a stored value stands against the true one, since alpha-beta only proves bounds on a cut off line
+const (
+	Exact = iota
+	LowerBound
+	UpperBound
+)
+
+// An Entry is what a search learned about a position
+type Entry struct {
+	Hash  Hash
+	Value int
+	Bound int // Exact, LowerBound or UpperBound
+	Depth int // how much searching went into the value; a deeper one is kept over a shallower one
+	Best  int // the best option found, -1 if there wasn't one
+}
+
+/*
+A Table remembers what searches learned about positions, so a position reached again by another order of
+play needn't be searched again.  It holds a fixed number of entries, picked by the hash, and when two
+positions want the same spot the one searched deeper stays.  It's safe for searches running at the same
+time to share one.
+*/
+type Table struct {
+	mu      sync.Mutex
+	entries []Entry
+	used    []bool
+	Hits    int
+	Misses  int
+}
+
+// NewTable makes a table with room for size entries, rounded up to a power of two
+func NewTable(size int) *Table {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	return &Table{entries: make([]Entry, n), used: make([]bool, n)}
+}
+
+func (t *Table) spot(h Hash) int {
+	return int(uint64(h) & uint64(len(t.entries)-1))
+}
+
+// Get looks up a position
+func (t *Table) Get(h Hash) (e Entry, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := t.spot(h)
+	if t.used[i] && t.entries[i].Hash == h {
+		t.Hits++
+		return t.entries[i], true
+	}
+	t.Misses++
+	return e, false
+}
+
+// Put stores what was learned, unless the spot holds a different position that was searched deeper
+func (t *Table) Put(e Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := t.spot(e.Hash)
+	if t.used[i] && t.entries[i].Hash != e.Hash && t.entries[i].Depth > e.Depth {
+		return
+	}
+	t.entries[i], t.used[i] = e, true
+}
+
+// Len is how many spots are filled
+func (t *Table) Len() (n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, used := range t.used {
+		if used {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+func cardNames(cards []*card.Card) string {
+	var names []string
+	for _, c := range cards {
+		if c != nil {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// seeing writes out everything in a view, to tell whether two views are the same
+func seeing(view *player.PlayerView) string {
+	output := fmt.Sprintf("seat %d turn %d stock %d opponent holds %d\nhand %s\ndiscard %s\ntrash %s\nknown %s\n",
+		view.Seat, view.Clock.Turn, view.Stock(), view.OpponentHand(), cardNames(view.Hand.Cards),
+		cardNames(view.DiscardPile.Cards[:view.DiscardPile.PullPos+1]), cardNames(view.Trash.Cards[:view.Trash.PullPos+1]),
+		cardNames(view.OpponentHolding()))
+	for _, tableau := range []*card.Tableau{view.Tableau, view.Opponent} {
+		for kind := 0; kind <= 9; kind++ {
+			if stack := tableau.Stack[kind]; stack != nil {
+				output += fmt.Sprintf("%s %s\n", card.KindName(kind), cardNames(stack.Cards))
+			}
+		}
+		output += fmt.Sprintf("storage %s\n", cardNames(tableau.Storage))
+	}
+	return output
+}
+
+// the decisions after which the seat has seen a card come out of hiding, or can't know what its opponent did
+var reveals = map[int]bool{DecideRedraw: true, DecideStore: true, DecideTrash: true, DecideDraw: true}
+
+/*
+TestViews makes sure no player decides with cards it can't see.  At the start of every turn of every game,
+the cards hidden from the seat to play, in the stock and the opponent's hand, are dealt again at random.
+The seat's view has to come out the same, and so do its hints and its decisions, up to the first one that
+brings a hidden card to light.
+*/
+func TestViews(t *testing.T) {
+	LogLevel, player.LogLevel = 0, 0
+	games := 200
+	if testing.Short() {
+		games = 20
+	}
+	rng := rand.New(rand.NewSource(1))
+	positions, decisions := 0, 0
+	for seed := int64(1); seed <= int64(games); seed++ {
+		g := New(Options{Seed: seed})
+		for !g.Over {
+			made, err := checkView(g, rng)
+			if err != nil {
+				t.Fatalf("seed %d turn %d player %d: %v", seed, g.Turn, g.ToMove, err)
+			}
+			positions++
+			decisions += made
+			g.PlayTurn()
+		}
+	}
+	t.Logf("%d positions, %d decisions made the same with the hidden cards dealt again", positions, decisions)
+}
+
+// checkView plays the next turn with the real hidden cards and with others, and compares the two
+func checkView(g *Game, rng *rand.Rand) (decisions int, err error) {
+	seat := g.ToMove
+	actual, other := g.Clone(), g.Clone()
+	other.Determinize(seat, rng)
+	if a, b := seeing(actual.Players[seat].PlayerView), seeing(other.Players[seat].PlayerView); a != b {
+		return 0, fmt.Errorf("the view changed with the hidden cards:\n%s\nand\n%s", a, b)
+	}
+
+	options := buildOptions(actual.Players[seat])
+	hints := actual.Hints(Decision{Type: DecideBuild, Seat: seat, Options: options})
+	for i, h := range other.Hints(Decision{Type: DecideBuild, Seat: seat, Options: options}) {
+		if h.Option.Label != hints[i].Option.Label || h.Value != hints[i].Value || h.Reason != hints[i].Reason {
+			return 0, fmt.Errorf("hint %q %d with the real hidden cards, %q %d with others", hints[i].Option.Label, hints[i].Value, h.Option.Label, h.Value)
+		}
+	}
+
+	for _, turn := range []*Game{actual, other} {
+		turn.RecordFromHere()
+		turn.PlayTurn()
+	}
+	seen, guessed := chosen(actual), chosen(other)
+	for i := 0; i < len(seen) && i < len(guessed); i++ {
+		a, b := seen[i], guessed[i]
+		if a.Seat != seat || b.Seat != seat {
+			break
+		}
+		if a != b {
+			return decisions, fmt.Errorf("%s with the real hidden cards, but %s with others", a, b)
+		}
+		decisions++
+		if reveals[a.Decision] {
+			break
+		}
+	}
+	return decisions, nil
+}
+
+// chosen picks the decisions out of the game log
+func chosen(g *Game) (events []Event) {
+	for _, e := range g.Events {
+		if e.Type == Chose {
+			events = append(events, e)
+		}
+	}
+	return
+}
@@ -0,0 +1,409 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// the answers that ask for a hint instead of making a choice
+var hintWords = map[string]bool{"h": true, "hint": true, "?": true}
+
+// the commands that can be typed at any question
+var anywhere = []string{"help", "hint", "show", "undo"}
+
+// the piles show can list
+var showable = []string{"discard", "trash", "hand", "storage", "tableau", "opponent"}
+
+const commandHelp = `Type a command, or the number from the menu:
+  build mine, upgrade farm    build a card, by its name or its kind
+  discard 2 4                 pay with the cards numbered 2 and 4, or give their names
+  attack storage              send your soldier after a card, by its kind or its name
+  store discard               fill storage from the discard, the stock, or a card in your hand
+  trash exchange              trash a card
+  pass                        don't build, attack or trash
+  y, n                        answer a question, or use its own words, like redraw, keep or defend
+  show trash                  list a pile: discard, trash, hand, storage, tableau or opponent
+  help cathedral              what a card does, or every card of a kind, like help farm
+  hint                        how the computer would rate your options
+  undo                        start picking your cards again
+Words can be cut short, like b mi for build mine, and tab finishes them.
+`
+
+/*
+A prompt is a question put to a human and the answers they can give.  Each answer has its number from the
+menu, and the names it goes by: a card's name and its kind, a pile, yes or no.  A command can start with one
+of the verbs that go with the question, like "build" or "discard", and some verbs are an answer on their
+own, like "pass".
+*/
+type prompt struct {
+	verbs   map[string]int   // the answer each verb gives on its own, or -1 if it needs to be told which
+	answers map[int][]string // the names of each answer, by its number on the menu
+	nine    bool             // 9 starts over, when picking more than one card
+}
+
+func newPrompt(verbs map[string]int) prompt {
+	if verbs == nil {
+		verbs = make(map[string]int)
+	}
+	return prompt{verbs: verbs, answers: make(map[int][]string)}
+}
+
+// add puts an answer on the prompt, with its names
+func (p prompt) add(number int, names ...string) {
+	list := p.answers[number]
+	for _, name := range names {
+		list = append(list, strings.ToLower(name))
+	}
+	p.answers[number] = list
+}
+
+// cardNames are the names a card goes by in a command, its own and its kind's
+func cardNames(c *card.Card) []string {
+	return []string{c.Name, card.KindName(c.Kind)}
+}
+
+// yesNo is a prompt with just the two answers, 1 for yes and 0 for no
+func yesNo(verbs map[string]int, yes ...string) prompt {
+	p := newPrompt(verbs)
+	p.add(1, append([]string{"yes"}, yes...)...)
+	p.add(0, "no")
+	return p
+}
+
+// a reply is what a command came to: the answers picked, by number, or undo
+type reply struct {
+	numbers []int
+	undo    bool
+}
+
+// words are everything that can be typed next on the line, for tab to finish
+func (p prompt) words(line string) []string {
+	fields := strings.Fields(strings.ToLower(line))
+	if strings.HasSuffix(line, " ") || line == "" {
+		fields = append(fields, "")
+	}
+	var words []string
+	if len(fields) == 1 {
+		words = append(words, anywhere...)
+		for verb := range p.verbs {
+			words = append(words, verb)
+		}
+	}
+	switch {
+	case len(fields) > 1 && strings.HasPrefix("help", fields[0]) && fields[0] != "h":
+		for _, c := range card.Deck {
+			words = append(words, strings.Fields(strings.ToLower(c.Name))...)
+		}
+		for kind := 0; kind <= 9; kind++ {
+			words = append(words, strings.ToLower(card.KindName(kind)))
+		}
+	case len(fields) > 1 && strings.HasPrefix("show", fields[0]):
+		words = append(words, showable...)
+	default:
+		for _, names := range p.answers {
+			for _, name := range names {
+				words = append(words, strings.Fields(name)...)
+			}
+		}
+	}
+	return completions(line, words)
+}
+
+// pick finds the one word in the list that the typed word is short for
+func pick(typed string, words []string) (string, error) {
+	var matches []string
+	for _, w := range words {
+		if w == typed {
+			return w, nil
+		}
+		if strings.HasPrefix(w, typed) {
+			matches = append(matches, w)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%q isn't one of %s", typed, strings.Join(words, ", "))
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("%q could be %s", typed, strings.Join(matches, " or "))
+}
+
+// fits gives how well the typed words go with a name: 3 for the whole name, 2 for the start of it, 1 for the
+// start of one of its words, 0 for not at all
+func fits(typed string, name string) int {
+	if typed == name {
+		return 3
+	}
+	if strings.HasPrefix(name, typed) {
+		return 2
+	}
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, typed) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// resolve works out which answers the words name, taking the longest run of words that names just one card,
+// so "town hall" is one answer but "mine church" is two.  Two copies of a card are told apart by taking the
+// first one that isn't picked yet.
+func (p prompt) resolve(fields []string) (numbers []int, err error) {
+	used := make(map[int]bool)
+	for i := 0; i < len(fields); {
+		if n, err := strconv.Atoi(fields[i]); err == nil {
+			if _, ok := p.answers[n]; !ok || used[n] {
+				return nil, fmt.Errorf("%d isn't on the menu", n)
+			}
+			used[n] = true
+			numbers = append(numbers, n)
+			i++
+			continue
+		}
+		found := false
+		for end := len(fields); end > i && !found; end-- {
+			typed := strings.Join(fields[i:end], " ")
+			best, matches := 0, []int(nil)
+			for n, names := range p.answers {
+				if used[n] {
+					continue
+				}
+				fit := 0
+				for _, name := range names {
+					if f := fits(typed, name); f > fit {
+						fit = f
+					}
+				}
+				if fit > best {
+					best, matches = fit, nil
+				}
+				if fit == best && fit > 0 {
+					matches = append(matches, n)
+				}
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			sort.Ints(matches)
+			// copies of the same card are as good as each other
+			for _, n := range matches[1:] {
+				if p.answers[n][0] != p.answers[matches[0]][0] {
+					var could []string
+					for _, m := range matches {
+						could = append(could, p.answers[m][0])
+					}
+					return nil, fmt.Errorf("%q could be %s", typed, strings.Join(could, " or "))
+				}
+			}
+			used[matches[0]] = true
+			numbers = append(numbers, matches[0])
+			i, found = end, true
+		}
+		if !found {
+			return nil, fmt.Errorf("there's nothing called %q to choose", fields[i])
+		}
+	}
+	return
+}
+
+// parse works out a command.  Commands that can be typed anywhere, like help, are done here and come back
+// with no reply.
+func (player Player) parse(line string, p prompt) (r reply, err error) {
+	fields := strings.Fields(strings.ToLower(line))
+	if len(fields) == 0 {
+		return
+	}
+	if hintWords[fields[0]] {
+		fields[0] = "hint"
+	}
+	if p.nine && len(fields) == 1 && fields[0] == "9" {
+		r.undo = true
+		return
+	}
+	verbs := append([]string(nil), anywhere...)
+	for verb := range p.verbs {
+		verbs = append(verbs, verb)
+	}
+	sort.Strings(verbs)
+	_, numberErr := strconv.Atoi(fields[0])
+	verb, verbErr := pick(fields[0], verbs)
+	if numberErr == nil || verbErr != nil {
+		// no verb, just the answers, like "2 4" or "mine"
+		if r.numbers, err = p.resolve(fields); err != nil {
+			if len(completions(fields[0], verbs)) > 1 {
+				// they were typing a verb, but didn't say enough of it
+				return r, verbErr
+			}
+			err = fmt.Errorf("%v, type help for the commands", err)
+		}
+		return
+	}
+	switch verb {
+	case "hint":
+		if player.Hint == nil {
+			player.UI.Notify("No hints in this game")
+		} else {
+			player.UI.Notify(player.Hint())
+		}
+		return
+	case "help":
+		text, err := help(fields[1:])
+		if err == nil {
+			player.UI.Notify(text)
+		}
+		return r, err
+	case "show":
+		text, err := player.showPile(fields[1:])
+		if err == nil {
+			player.UI.Notify(text)
+		}
+		return r, err
+	case "undo":
+		r.undo = true
+		return
+	}
+	if len(fields) == 1 {
+		if answer := p.verbs[verb]; answer >= 0 {
+			r.numbers = []int{answer}
+			return
+		}
+		return r, fmt.Errorf("%s what?", verb)
+	}
+	r.numbers, err = p.resolve(fields[1:])
+	return
+}
+
+// help tells what a card does, or every card of a kind
+func help(fields []string) (text string, err error) {
+	if len(fields) == 0 {
+		return commandHelp, nil
+	}
+	typed := strings.Join(fields, " ")
+	for kind := 0; kind <= 9; kind++ {
+		if fits(typed, strings.ToLower(card.KindName(kind))) >= 2 {
+			for _, c := range card.Deck {
+				if c.Kind == kind {
+					text += fmt.Sprintf("%s: %s\n", c, c.Rule)
+				}
+			}
+			return
+		}
+	}
+	for _, c := range card.Deck {
+		if fits(typed, strings.ToLower(c.Name)) > 0 {
+			text += fmt.Sprintf("%s: %s\n", c, c.Rule)
+		}
+	}
+	if text == "" {
+		return "", fmt.Errorf("there's no card called %q", typed)
+	}
+	return
+}
+
+// showPile lists the cards in one of the places the player can see
+func (player Player) showPile(fields []string) (text string, err error) {
+	if len(fields) == 0 {
+		return "", fmt.Errorf("show what? %s", strings.Join(showable, ", "))
+	}
+	which, err := pick(fields[0], showable)
+	if err != nil {
+		return "", err
+	}
+	list := func(title string, cards []*card.Card) {
+		text += title + "\n"
+		count := 0
+		for _, c := range cards {
+			if c != nil {
+				text += fmt.Sprintf("  %s: %s\n", c, c.Rule)
+				count++
+			}
+		}
+		if count == 0 {
+			text += "  nothing\n"
+		}
+	}
+	switch which {
+	case "discard":
+		list("The discard pile, top first:", pileCards(player.DiscardPile))
+	case "trash":
+		list("The trash, top first:", pileCards(player.Trash))
+	case "hand":
+		list("Your hand:", player.Hand.Cards)
+	case "storage":
+		list("Your storage:", player.Tableau.Storage)
+		list("Your opponent's storage:", player.Opponent.Storage)
+	case "tableau":
+		text = fmt.Sprintf("Your tableau:\n%s", player.Tableau)
+	case "opponent":
+		text = fmt.Sprintf("Your opponent's tableau, with %d cards in their hand:\n%s", player.OpponentHand(), player.Opponent)
+	}
+	return
+}
+
+// pileCards are the cards in a face up pile, top first
+func pileCards(pile *card.Hand) (cards []*card.Card) {
+	for i := pile.PullPos; i >= 0; i-- {
+		cards = append(cards, pile.Cards[i])
+	}
+	return
+}
+
+/*
+ask reads answers to the prompt until one makes sense.  The first is read with first, which puts the
+question up; after that the question is asked again on its own.  Help, hints and the like are dealt with on
+the way, and a command that doesn't make sense says why.
+*/
+func (player Player) ask(p prompt, question string, first func() (string, error)) reply {
+	read := first
+	for {
+		line, err := read()
+		if err != nil {
+			player.UI.Notify("There's no more to read, so the game stops here")
+			os.Exit(1)
+		}
+		read = func() (string, error) { return player.UI.Prompt(question, p.words) }
+		r, err := player.parse(line, p)
+		if err != nil {
+			player.UI.Notify(err.Error())
+			continue
+		}
+		if r.undo || len(r.numbers) > 0 {
+			return r
+		}
+	}
+}
+
+// askYes asks a yes or no question until it gets an answer
+func (player Player) askYes(title, question string, p prompt) bool {
+	first := func() (string, error) { return player.UI.Confirm(title, question, p.words) }
+	for {
+		r := player.ask(p, question, first)
+		if !r.undo {
+			return r.numbers[0] == 1
+		}
+		player.UI.Notify("There's nothing to undo")
+		first = func() (string, error) { return player.UI.Prompt(question, p.words) }
+	}
+}
+
+// news is what's happened since the human's last turn, if there's anyone keeping track
+func (player Player) news() string {
+	if player.News == nil {
+		return ""
+	}
+	return player.News()
+}
+
+// analyse shows the hint before a human decides, in analysis mode
+func (player Player) analyse() {
+	if player.Analysis && player.Hint != nil {
+		player.UI.Notify(player.Hint())
+	}
+}
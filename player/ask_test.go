@@ -0,0 +1,169 @@
+package player
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// a notes UI keeps what the player is told, and has nothing to read
+type notes struct {
+	told []string
+}
+
+func (n *notes) Render(view *PlayerView, news string) {}
+func (n *notes) Choose(menu Menu, complete func(line string) []string) (string, error) {
+	return "", nil
+}
+func (n *notes) Confirm(title, question string, complete func(line string) []string) (string, error) {
+	return "", nil
+}
+func (n *notes) Prompt(question string, complete func(line string) []string) (string, error) {
+	return "", nil
+}
+func (n *notes) Notify(message string) { n.told = append(n.told, message) }
+func (n *notes) Wait(message string)   {}
+
+// named gives a copy of the card from the deck
+func named(name string) *card.Card {
+	c := card.Deck[card.IndexByName(name)]
+	return &c
+}
+
+// discarding is the prompt for picking two cards to discard from a hand of Mine, Chapel, Town Hall and
+// another Mine, as humanChooses puts it together
+func discarding() prompt {
+	p := newPrompt(map[string]int{"discard": -1})
+	p.nine = true
+	for n, name := range []string{"Mine", "Chapel", "Town Hall", "Mine"} {
+		p.add(n+1, cardNames(named(name))...)
+	}
+	return p
+}
+
+// TestPick cuts verbs short, sometimes too short to tell which one is meant
+func TestPick(t *testing.T) {
+	verbs := []string{"build", "help", "hint", "pass", "show", "undo", "upgrade"}
+	for _, test := range []struct {
+		typed string
+		words []string
+		want  string
+		err   string
+	}{
+		{"b", verbs, "build", ""},
+		{"up", verbs, "upgrade", ""},
+		{"pass", []string{"pass", "passage"}, "pass", ""},
+		{"u", verbs, "", `"u" could be undo or upgrade`},
+		{"h", verbs, "", `"h" could be help or hint`},
+		{"x", []string{"discard", "stock"}, "", `"x" isn't one of discard, stock`},
+	} {
+		got, err := pick(test.typed, test.words)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("pick(%q) gave %q and the error %v, not %s", test.typed, got, err, test.err)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("pick(%q) gave %q and the error %v, not %q", test.typed, got, err, test.want)
+		}
+	}
+}
+
+// TestWords finishes words with tab: verbs and answers first, then what goes with the verb
+func TestWords(t *testing.T) {
+	if got, want := completions("bu", []string{"build", "burn", "build", "pass"}), []string{"build", "burn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions gave %q, not %q", got, want)
+	}
+	p := discarding()
+	for _, test := range []struct {
+		line string
+		want []string
+	}{
+		{"", []string{"chapel", "civic", "discard", "hall", "help", "hint", "mine", "show", "supply", "town", "undo"}},
+		{"d", []string{"discard"}},
+		{"discard ", []string{"chapel", "civic", "hall", "mine", "supply", "town"}},
+		{"discard t", []string{"town"}},
+		{"2 c", []string{"chapel", "civic"}},
+		{"show t", []string{"tableau", "trash"}},
+		{"help cat", []string{"cathedral"}},
+		{"help mil", []string{"military", "militia"}},
+	} {
+		if got := p.words(test.line); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("after %q, tab gave %q, not %q", test.line, got, test.want)
+		}
+	}
+}
+
+/*
+TestParse types commands at a build and a discard menu: cards by number, name or kind, cut short or not,
+9 and undo to start over, and the commands that can be typed anywhere.
+*/
+func TestParse(t *testing.T) {
+	chapel := named("Chapel")
+	discard := &card.Hand{Cards: []*card.Card{chapel, nil}, PullPos: 0}
+	trash := &card.Hand{Cards: make([]*card.Card, 2), PullPos: -1}
+	building := newPrompt(map[string]int{"build": -1, "upgrade": -1, "pass": 0})
+	building.add(0)
+	building.add(1, cardNames(named("Mine"))...)
+	one := discarding()
+	one.nine = false
+	for _, test := range []struct {
+		line    string
+		p       prompt
+		numbers []int
+		undo    bool
+		told    string // what the player is told, if anything
+		err     string
+	}{
+		{line: "discard 2 4", p: discarding(), numbers: []int{2, 4}},
+		{line: "2 4", p: discarding(), numbers: []int{2, 4}},
+		{line: "d 3", p: discarding(), numbers: []int{3}},
+		{line: "discard mine chapel", p: discarding(), numbers: []int{1, 2}},
+		{line: "mine mine", p: discarding(), numbers: []int{1, 4}},
+		{line: "town hall", p: discarding(), numbers: []int{3}},
+		{line: "to", p: discarding(), numbers: []int{3}},
+		{line: "civic", p: discarding(), err: `"civic" could be chapel or town hall, type help for the commands`},
+		{line: "2 2", p: discarding(), err: "2 isn't on the menu, type help for the commands"},
+		{line: "discard", p: discarding(), err: "discard what?"},
+		{line: "castle", p: discarding(), err: `there's nothing called "castle" to choose, type help for the commands`},
+		{line: "9", p: discarding(), undo: true},
+		{line: "9", p: one, err: "9 isn't on the menu, type help for the commands"},
+		{line: "undo", p: discarding(), undo: true},
+		{line: "pass", p: building, numbers: []int{0}},
+		{line: "b mi", p: building, numbers: []int{1}},
+		{line: "supply", p: building, numbers: []int{1}},
+		{line: "u", p: building, err: `"u" could be undo or upgrade`},
+		{line: "h", p: building, told: "No hints in this game"},
+		{line: "help", p: building, told: commandHelp},
+		{line: "help chapel", p: building, told: "Chapel(Civic 1 : stone): +1 VP\n"},
+		{line: "help nothing", p: building, err: `there's no card called "nothing"`},
+		{line: "show d", p: building, told: "The discard pile, top first:\n  Chapel(Civic 1 : stone): +1 VP\n"},
+		{line: "show trash", p: building, told: "The trash, top first:\n  nothing\n"},
+		{line: "show", p: building, err: "show what? discard, trash, hand, storage, tableau, opponent"},
+		{line: "show t", p: building, err: `"t" could be tableau or trash`},
+	} {
+		ui := &notes{}
+		player := Player{PlayerView: &PlayerView{DiscardPile: discard, Trash: trash}, Human: true, UI: ui}
+		r, err := player.parse(test.line, test.p)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q gave the error %v, not %s", test.line, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q gave the error %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.numbers, test.numbers) || r.undo != test.undo {
+			t.Errorf("%q came to %v, undo %t, not %v, undo %t", test.line, r.numbers, r.undo, test.numbers, test.undo)
+		}
+		told := strings.Join(ui.told, "\n")
+		if told != test.told {
+			t.Errorf("%q told the player %q, not %q", test.line, told, test.told)
+		}
+	}
+}
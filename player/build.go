@@ -0,0 +1,110 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+// how many builds ahead the computer looks when it has bonus builds
+const planDepth = 3
+
+// BuildCost is how many cards must be discarded to build the card, and whether it's a free upgrade
+func (player Player) BuildCost(thiscard *card.Card) (cost int, upgrade bool) {
+	top := player.TopCard(thiscard.Kind)
+	if top != nil && top.Cost == thiscard.Cost-1 {
+		return 0, true
+	}
+	return thiscard.Cost + player.Tableau.Discounts[thiscard.Material], false
+}
+
+// BuildScore is the value of the card less the value of the cards it burns, and the discards that
+// would be made.  An upgrade burns nothing.  Cards in the hand are only worth something if they get built
+// one day, so the personality's DiscardWeight scales how much of their value counts against the build.
+func (player Player) BuildScore(pos Pos, phase int) (score int, discards []Pos, upgrade bool) {
+	thiscard := player.CardByPos(pos)
+	cost, upgrade := player.BuildCost(thiscard)
+	score = player.CardValue(thiscard, phase)
+	if cost > 0 {
+		discards = player.ChooseDiscards(pos, cost, phase)
+		for _, discard := range discards {
+			score -= player.CardValue(player.CardByPos(discard), phase) * player.traits().DiscardWeight / 100
+		}
+	}
+	return
+}
+
+/*
+planBuilds looks for the best sequence of up to buildsLeft builds, scoring each by BuildScore, and gives
+back the first build of that sequence.  Building a School adds builds straight away, so those are counted
+in as well.  If mustBuild is set the best build is taken even when it scores below zero, otherwise the
+player would rather stop.  depth limits how far ahead we look.
+*/
+func (player Player) planBuilds(allowedFrom map[int]bool, phase int, buildsLeft int, mustBuild bool, depth int) (first Pos, score int) {
+	first = Pos{NoCard, -1}
+	score = 0
+	if buildsLeft < 1 || depth < 1 {
+		return
+	}
+	found := false
+	for space := FromHand; space <= FromStorage; space++ {
+		if !allowedFrom[space] {
+			continue
+		}
+		var cardrange []*card.Card
+		if space == FromHand {
+			cardrange = player.Hand.Cards
+		} else {
+			cardrange = player.Tableau.Storage
+		}
+		for id, thiscard := range cardrange {
+			if thiscard == nil {
+				continue
+			}
+			pos := Pos{space, id}
+			if isBuildable, _ := cardIsBuildable(pos, *thiscard, player); !isBuildable {
+				continue
+			}
+			sequence, discards, upgrade := player.BuildScore(pos, phase)
+
+			// see what we could do with the builds that are left
+			next := player.clone()
+			next.Build(pos, discards, nil, upgrade)
+			left := buildsLeft - 1 + next.Tableau.BuildBonus - player.Tableau.BuildBonus
+			if left > 0 {
+				_, rest := next.planBuilds(allowedFrom, phase, left, false, depth-1)
+				sequence += rest
+			}
+
+			if (!found && mustBuild) || sequence > score {
+				found = true
+				first = pos
+				score = sequence
+			}
+		}
+	}
+	return
+}
+
+// clone copies the player's hand and tableau, so we can try out a build without changing the real ones
+func (player Player) clone() Player {
+	c := player
+	view := *player.PlayerView
+	c.PlayerView = &view
+	hand := *player.Hand
+	hand.Cards = append([]*card.Card(nil), player.Hand.Cards...)
+	c.Hand = &hand
+
+	tableau := *player.Tableau
+	tableau.Stack = make(map[int]*card.Hand)
+	for kind, stack := range player.Tableau.Stack {
+		if stack == nil {
+			continue
+		}
+		s := *stack
+		s.Cards = append([]*card.Card(nil), stack.Cards...)
+		tableau.Stack[kind] = &s
+	}
+	tableau.Storage = append([]*card.Card(nil), player.Tableau.Storage...)
+	tableau.Discounts = append([]int(nil), player.Tableau.Discounts...)
+	c.Tableau = &tableau
+	return c
+}
@@ -0,0 +1,128 @@
+package player
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+A Console is the UI for a human at a terminal, or anything that reads and writes like one.  The table is drawn
+as a full screen with the menu in a panel under it (see screen.go), and answers are read a line at a time.
+When In is a terminal its keys are read as they're typed, so tab can finish words.  Given a script of answers
+and somewhere to write, it plays a human's side of a game without a terminal at all:
+
+	ui := player.NewConsole(strings.NewReader("build mine\ndiscard 2\n"), &out)
+
+Two humans can share a console, taking turns at the one terminal.  When it's given the other seat's view to
+render, it clears the screen and waits for the device to be passed over (see handOver), so neither sees the
+other's hand.
+*/
+type Console struct {
+	In    io.Reader
+	Out   io.Writer
+	Plain bool // leave out the escape codes, for a terminal that doesn't take them or output that isn't one
+
+	in      *bufio.Reader
+	view    *PlayerView // the table as last rendered, drawn again under each question
+	news    string
+	pending string // messages that came after Render, to go in the panel with the next question
+	fresh   bool   // the table has been rendered but not shown
+}
+
+// NewConsole makes a console reading answers from in and writing to out
+func NewConsole(in io.Reader, out io.Writer) *Console {
+	return &Console{In: in, Out: out, in: bufio.NewReader(in)}
+}
+
+// raw puts the terminal in raw mode, if In is one
+func (c *Console) raw() (restore func(), err error) {
+	f, ok := c.In.(*os.File)
+	if !ok {
+		return nil, errors.New("not a terminal")
+	}
+	return rawMode(f)
+}
+
+// Render keeps the table to draw with the next question, after handing over to the seat if it's changed
+func (c *Console) Render(view *PlayerView, news string) {
+	if c.view != nil && c.view.Seat != view.Seat {
+		c.handOver(view.Seat, news)
+	}
+	c.view, c.news, c.fresh = view, news, true
+}
+
+// handOver hides the table and says what's happened since the next player's last turn, then waits for them
+// to be the one at the screen.  A plain console can't clear the screen, so it scrolls the table away.
+func (c *Console) handOver(seat int, news string) {
+	// anything still waiting to be said was for the last player
+	c.pending = ""
+	if c.Plain {
+		fmt.Fprint(c.Out, strings.Repeat("\n", screenLines))
+	} else {
+		fmt.Fprint(c.Out, home+clearAll)
+	}
+	fmt.Fprintf(c.Out, "%s\n\n", c.paint(fmt.Sprintf(" PASS TO PLAYER %d ", seat), bold, inverse))
+	if news != "" {
+		fmt.Fprintln(c.Out, "Since your last turn:")
+		for _, line := range strings.Split(strings.TrimRight(news, "\n"), "\n") {
+			fmt.Fprintf(c.Out, "  %s\n", line)
+		}
+		fmt.Fprintln(c.Out)
+	}
+	// running out of input here comes up again at the question
+	c.Prompt(fmt.Sprintf("Press enter when only player %d can see the screen", seat), func(string) []string { return nil })
+}
+
+// Choose draws the table with the menu under it, and reads the answer
+func (c *Console) Choose(menu Menu, complete func(line string) []string) (string, error) {
+	panel := fmt.Sprintf("-=* %s *=-\n", strings.ToUpper(menu.Title))
+	for _, item := range menu.Items {
+		if item.Reason != "" {
+			panel += fmt.Sprintf("   %s (%s)\n", item.Label, item.Reason)
+			continue
+		}
+		panel += fmt.Sprintf("%d. %s\n", item.Number, item.Label)
+	}
+	if menu.Count > 1 {
+		panel += fmt.Sprintf("Choose %d\n", menu.Count)
+	}
+	c.show(panel)
+	return c.Prompt(menu.Question, complete)
+}
+
+// Confirm draws the table with the question under it, and reads the answer
+func (c *Console) Confirm(title, question string, complete func(line string) []string) (string, error) {
+	c.show(fmt.Sprintf("-=* %s *=-\n", strings.ToUpper(title)))
+	return c.Prompt(question, complete)
+}
+
+// Prompt asks the question and reads the answer
+func (c *Console) Prompt(question string, complete func(line string) []string) (string, error) {
+	fmt.Fprintln(c.Out, question)
+	return c.readLine(complete)
+}
+
+// Wait draws the table with the message under it, and nothing to answer
+func (c *Console) Wait(message string) {
+	if message != "" {
+		message += "\n"
+	}
+	c.show(message)
+}
+
+// Notify writes out the message.  Between Render and the question, it waits to go under the menu, since the
+// screen is about to be cleared.
+func (c *Console) Notify(message string) {
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	if c.fresh {
+		c.pending += message
+		return
+	}
+	fmt.Fprint(c.Out, message)
+}
@@ -0,0 +1,126 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+// rough rates for guessing how long a game has left: each round the two players pull about 4 cards off the
+// stock between them, and a player fills a new space in their tableau about every other turn
+const stockPerRound = 4
+const roundsPerFill = 2
+
+/*
+A Clock tells the players how close the game is to ending, which happens when the stock runs out or someone
+fills all 9 spaces of their tableau.  The engine keeps Turn up to date.
+*/
+type Clock struct {
+	Stock   func() int // how many cards are left in the stock
+	Turn    int
+	TurnCap int // 0 if there is no cap
+}
+
+// RoundsLeft guesses how many more rounds the game will last, given both players' tableau fill
+func (c *Clock) RoundsLeft(fills ...int) (rounds int) {
+	rounds = (c.Stock() + stockPerRound - 1) / stockPerRound
+	for _, fill := range fills {
+		if byFill := (9 - fill) * roundsPerFill; byFill < rounds {
+			rounds = byFill
+		}
+	}
+	if c.TurnCap > 0 && c.TurnCap-c.Turn < rounds {
+		rounds = c.TurnCap - c.Turn
+	}
+	if rounds < 0 {
+		rounds = 0
+	}
+	return
+}
+
+// Phase picks the column of the strategy to use: 0 early, 1 middle and 2 late in the game
+func (c *Clock) Phase(fills ...int) int {
+	rounds := c.RoundsLeft(fills...)
+	if rounds >= 12 {
+		return 0
+	} else if rounds >= 7 {
+		return 1
+	}
+	return 2
+}
+
+// RoundsLeft is the Clock's guess from this player's point of view, -1 if there is no clock
+func (player Player) RoundsLeft() int {
+	if player.Clock == nil {
+		return -1
+	}
+	fills := []int{player.Tableau.Fill}
+	if player.Opponent != nil {
+		fills = append(fills, player.Opponent.Fill)
+	}
+	return player.Clock.RoundsLeft(fills...)
+}
+
+// Urgency runs from 0, with plenty of game left, to 1 on what is probably the last round
+func (player Player) Urgency() float64 {
+	rounds := player.RoundsLeft()
+	if rounds < 0 || rounds >= 6 {
+		return 0
+	}
+	if rounds < 1 {
+		return 1
+	}
+	return float64(6-rounds) / 5
+}
+
+// VPGap is how far ahead we are on the table, negative when we're behind
+func (player Player) VPGap() (gap int) {
+	gap = player.VictoryPoints()
+	if player.Opponent != nil {
+		for kind := 0; kind <= 9; kind++ {
+			if top := player.Opponent.Top(kind); top != nil {
+				gap -= top.VictoryPoints
+			}
+		}
+	}
+	return
+}
+
+/*
+endgameAdjust changes the value of a card we might build as the end gets near.  Cards that score are worth
+more, while a card whose only worth is its power, or an upgrade we can't reach in the turns left, is
+worth less.
+*/
+func (player Player) endgameAdjust(thiscard *card.Card, value int) int {
+	urgency := player.Urgency()
+	if urgency == 0 {
+		return value
+	}
+	vp := thiscard.VictoryPoints
+	if top := player.TopCard(thiscard.Kind); top != nil {
+		if steps := thiscard.Cost - 1 - top.Cost; steps > 0 && steps >= player.RoundsLeft() {
+			// we'd need more upgrades first than there are turns to build them
+			return 0
+		}
+		vp -= top.VictoryPoints
+	}
+	if vp <= 0 {
+		// its power has fewer and fewer turns to pay off
+		return int(float64(value) * (1 - 0.75*urgency))
+	}
+	return value + int(urgency*float64(16*vp))
+}
+
+// AttackValue is how much we want to take the opponent's card.  Late in the game the VP it takes off them
+// counts for a lot, especially when they're ahead.
+func (player Player) AttackValue(target *card.Card, phase int) int {
+	value := player.CardValue(target, phase)
+	urgency := player.Urgency()
+	if player.VPGap() < 0 {
+		urgency *= 2
+	}
+	return value + int(urgency*float64(16*target.VictoryPoints))
+}
+
+// blocking is true when it's late and the opponent is ahead, so any soldier should go after them
+func (player Player) blocking() bool {
+	return player.Urgency() >= 0.5 && player.VPGap() < 0
+}
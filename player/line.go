@@ -0,0 +1,115 @@
+package player
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+readLine reads a line the human types.  At a terminal each key comes straight through, so tab can finish the
+word being typed from the words complete gives for the line so far, or list them when there's more than one
+way to go.  Without a terminal the line comes as the terminal gives it, and a line ending in a tab just lists
+the ways its last word could go, coming back empty so the question is asked again.
+*/
+func (c *Console) readLine(complete func(line string) []string) (string, error) {
+	restore, err := c.raw()
+	if err != nil {
+		line, err := c.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasSuffix(line, "\t") {
+			fmt.Fprintln(c.Out, strings.Join(complete(strings.TrimRight(line, "\t")), "  "))
+			return "", nil
+		}
+		return line, nil
+	}
+	defer restore()
+
+	var line []rune
+	add := func(text string) {
+		line = append(line, []rune(text)...)
+		fmt.Fprint(c.Out, text)
+	}
+	for {
+		key, _, err := c.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case '\r', '\n':
+			fmt.Fprintln(c.Out)
+			return string(line), nil
+		case 3: // control-C
+			restore()
+			fmt.Fprintln(c.Out, "^C")
+			os.Exit(130)
+		case 4: // control-D, on an empty line
+			if len(line) == 0 {
+				fmt.Fprintln(c.Out)
+				return "", io.EOF
+			}
+		case 127, '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Fprint(c.Out, "\b \b")
+			}
+		case 21: // control-U
+			fmt.Fprint(c.Out, strings.Repeat("\b \b", len(line)))
+			line = line[:0]
+		case 27: // the arrow keys and the like send an escape sequence, which isn't any use here
+			c.in.ReadRune()
+			c.in.ReadRune()
+		case '\t':
+			typed := string(line)
+			word := typed[strings.LastIndex(typed, " ")+1:]
+			words := complete(typed)
+			if len(words) == 0 {
+				fmt.Fprint(c.Out, "\a")
+				continue
+			}
+			if rest := commonPrefix(words)[len(word):]; rest != "" {
+				add(rest)
+			} else if len(words) > 1 {
+				fmt.Fprintf(c.Out, "\n%s\n%s", strings.Join(words, "  "), typed)
+			}
+			if len(words) == 1 {
+				add(" ")
+			}
+		default:
+			if key >= ' ' {
+				add(string(key))
+			}
+		}
+	}
+}
+
+// commonPrefix is what all the words start with
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// completions are the words that start with what's typed of the last word of the line, in order
+func completions(line string, words []string) []string {
+	typed := strings.ToLower(line[strings.LastIndex(line, " ")+1:])
+	seen := make(map[string]bool)
+	var list []string
+	for _, w := range words {
+		if strings.HasPrefix(w, typed) && !seen[w] {
+			seen[w] = true
+			list = append(list, w)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
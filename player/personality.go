@@ -0,0 +1,100 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A Personality changes how a computer player makes its decisions, on top of the values in its strategy.
+Card values run from 0 to 63, so the thresholds here are on that scale.
+*/
+type Personality struct {
+	Name          string
+	Description   string
+	KindBonus     [10]int // added to the value of each card kind, for building, keeping and drawing it
+	UpgradeBonus  int     // added to the value of a card that upgrades one already built
+	DiscardWeight int     // the percent of a discarded card's value that counts against building with it
+	AttackAt      int     // only attack for a card worth at least this much to us
+	KeepsSoldiers bool    // hold soldiers back for defense while the opponent can raise an army
+	DefendAt      int     // use a soldier to defend a card worth at least this much, when the rules allow defending
+	TrashAt       int     // don't trash a card worth more than this while within the hand limit
+	StoreAt       int     // fill storage from the hand or discard with a card worth at least this, else from the stock
+	DrawAt        int     // draw the top of the discard when it's worth more than this
+}
+
+// Heuristic is the personality the computer players have always had
+var Heuristic = Personality{
+	Name:          "heuristic",
+	Description:   "takes cards by their strategy value, attacks whenever it can",
+	DiscardWeight: 25,
+	AttackAt:      0,
+	DefendAt:      32,
+	TrashAt:       31,
+	StoreAt:       32,
+	DrawAt:        31,
+}
+
+var Personalities = map[string]Personality{
+	"heuristic": Heuristic,
+	"warlord": {
+		Name:          "warlord",
+		Description:   "rushes Military and Soldiers, and spends soldiers attacking rather than defending",
+		KindBonus:     kindBonus(map[int]int{card.Military: 24, card.Soldiers: 24, card.School: 8, card.Farm: 8}),
+		DiscardWeight: 25,
+		AttackAt:      0,
+		DefendAt:      56,
+		TrashAt:       31,
+		StoreAt:       32,
+		DrawAt:        31,
+	},
+	"builder": {
+		Name:          "builder",
+		Description:   "builds Civic buildings and anything with VP, and upgrades when it can",
+		KindBonus:     kindBonus(map[int]int{card.Civic: 24, card.School: 8, card.Soldiers: -16}),
+		UpgradeBonus:  12,
+		DiscardWeight: 30,
+		AttackAt:      40,
+		DefendAt:      40,
+		TrashAt:       24,
+		StoreAt:       40,
+		DrawAt:        36,
+	},
+	"turtle": {
+		Name:          "turtle",
+		Description:   "gets a Defensive building up first and keeps its soldiers home to defend",
+		KindBonus:     kindBonus(map[int]int{card.Defensive: 32, card.Military: 8, card.Soldiers: 8}),
+		DiscardWeight: 25,
+		AttackAt:      48,
+		KeepsSoldiers: true,
+		DefendAt:      0,
+		TrashAt:       24,
+		StoreAt:       32,
+		DrawAt:        40,
+	},
+	"merchant": {
+		Name:          "merchant",
+		Description:   "builds Market and Storage, and works the discard pile and the trash",
+		KindBonus:     kindBonus(map[int]int{card.Market: 24, card.Storage: 24, card.Supply: 8, card.Manufacturing: 8}),
+		DiscardWeight: 20,
+		AttackAt:      32,
+		DefendAt:      32,
+		TrashAt:       40,
+		StoreAt:       24,
+		DrawAt:        24,
+	},
+}
+
+func kindBonus(bonus map[int]int) (kinds [10]int) {
+	for kind, value := range bonus {
+		kinds[kind] = value
+	}
+	return
+}
+
+// the personality to use for decisions, the original heuristic if none was set
+func (player Player) traits() *Personality {
+	if player.Personality == nil {
+		return &Heuristic
+	}
+	return player.Personality
+}
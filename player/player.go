@@ -2,18 +2,29 @@ package player
 
 import (
 	"fmt"
-	"strings"
 	"github.com/chrislunt/warwick/card"
-	"os"
-	"os/exec"
 )
 
+// A Player decides for a seat, knowing only what's in its view
 type Player struct {
-	Hand *card.Hand
-	Tableau *card.Tableau
+	*PlayerView
 	Strategy [][][]int // the inputs are the turn, the card kind, and the card cost
 	Human bool
-	State string // when playing with a human, this give you a place to store the current state to share with the player
+	SpendStorage bool // a rule variant: whether cards in storage may be discarded to pay for a build, or trashed
+	Personality *Personality // how a computer player makes decisions, nil for the original heuristic
+	UI UI // for a human, how they see the game and answer
+	Hint func() string // for a human, rates the options of the decision in front of them
+	News func() string // for a human, what's happened since their last turn
+	Analysis bool // show a human the hint at every decision, without them asking
+}
+
+// LogLevel controls how chatty the computer players are.  Simulations turn it down to 0.
+var LogLevel = 2
+
+func log(level int, message string) {
+	if LogLevel >= level {
+		fmt.Println(message)
+	}
 }
 
 // These represent the places a player could choose cards from
@@ -30,18 +41,14 @@ var legalStoreFrom = map[int] bool{
 	FromDiscard: true,
 }
 
-var legalDiscardFrom = map[int] bool{
-	FromHand: 	true,
-	FromStorage: true,
-	FromStock: 	false,
-	FromDiscard: false,
-}
-
-var legalTrashFrom = map[int] bool{
-	FromHand:	true,
-	FromStorage: true,
-	FromStock:	false,
-	FromDiscard: false,
+// discards and trash come from the same places, and storage only under some rules
+func (player Player) spendFrom() map[int] bool {
+	return map[int] bool{
+		FromHand: 	true,
+		FromStorage: player.SpendStorage,
+		FromStock: 	false,
+		FromDiscard: false,
+	}
 }
 
 // This represents the place you can get a card from
@@ -59,22 +66,17 @@ func everythingIsAwesome(Pos, card.Card, Player) (bool, string) {
 }
 
 
-// This is for building
-func (player Player) PlayerChooses(allowedFrom map[int] bool, phase int) (pos Pos, cost int, upgrade bool) {
+// This is for building.  builds is how many cards the player has already built this turn.
+func (player Player) PlayerChooses(allowedFrom map[int] bool, phase int, builds int) (pos Pos, cost int, upgrade bool) {
 	cost = 0
 	upgrade = false
 	if player.Human {
-		choices := player.humanChooses("build", allowedFrom, nil, nil, cardIsBuildable, true, 1) // stock, discardPile, checkBuildable, passAllowed, selectCount
+		choices := player.humanChooses("build", allowedFrom, cardIsBuildable, true, 1) // checkBuildable, passAllowed, selectCount
 		pos = choices[0] // you can only choose 1 card to build at a time
 		if pos.From == NoCard {
 			return
 		}
-		thiscard := player.CardByPos(pos)
-		if player.TopCard(thiscard.Kind) != nil && player.TopCard(thiscard.Kind).Cost == thiscard.Cost - 1 {
-			upgrade = true
-		} else {
-			cost = thiscard.Cost + player.Tableau.Discounts[thiscard.Material]
-		}
+		cost, upgrade = player.BuildCost(player.CardByPos(pos))
 		return
 	} 
 	pos.From = NoCard // this means there's no legal build
@@ -82,47 +84,26 @@ func (player Player) PlayerChooses(allowedFrom map[int] bool, phase int) (pos Po
 	cost = -1
 	upgrade = false
 
-	for space := 1; space <= 4; space++ {
-		if !allowedFrom[space] {
-			continue
-		}
-		var cardrange []*card.Card
-		if space == FromHand {
-			cardrange = player.Hand.Cards
-		} else if space == FromStorage {
-			cardrange = player.Tableau.Storage
-		}
-		value := 0 // 0 to 63
-		for id, thiscard := range cardrange {
-			if thiscard == nil {
-				continue;
-			}
-			isBuildable, _ := cardIsBuildable(Pos{space, id}, *thiscard, player)
-			if (!isBuildable) {
-				continue;
-			}
+	// the first build of the turn is always taken if there is one, since building is how you score,
+	// but a bonus build is only worth it if the card is worth more than what it burns
+	buildsLeft := player.Tableau.BuildBonus + 1 - builds
+	plan, _ := player.planBuilds(allowedFrom, phase, buildsLeft, builds == 0, planDepth)
+	if plan.From != NoCard {
+		pos = plan
+		cost, upgrade = player.BuildCost(player.CardByPos(pos))
+	}
+	return
+}
 
-			if (pos.From == NoCard) || (player.CardValue(thiscard, phase) > value) {
-			// compare the value of this card to the current high
 
-				// this is now our new high
-				pos.From = space
-				pos.Index = id
-				cost = thiscard.Cost + player.Tableau.Discounts[thiscard.Material]
-				upgrade = false
-				if player.Tableau.Stack[thiscard.Kind] != nil {
-					pullPos := player.Tableau.Stack[thiscard.Kind].PullPos
-					if player.Tableau.Stack[thiscard.Kind].Cards[pullPos].Cost == thiscard.Cost - 1 {
-						upgrade = true
-						cost = 0
-					}
-				}
-				value = player.CardValue(thiscard, phase)
-				// TODO: modify the value of the card based on the cost
-			}
-		}
+// CanBuild says whether the card at pos may be built this turn
+func (player Player) CanBuild(pos Pos) bool {
+	thiscard := player.CardByPos(pos)
+	if thiscard == nil {
+		return false
 	}
-	return
+	buildable, _ := cardIsBuildable(pos, *thiscard, player)
+	return buildable
 }
 
 
@@ -156,11 +137,16 @@ func cardIsBuildable(pos Pos, thiscard card.Card, player Player) (buildable bool
 		discountedCost := thiscard.Cost + player.Tableau.Discounts[thiscard.Material]
 		// -1 to count because you must account for the card itself
 		availableCards := player.Hand.Count - 1
-		// Add in the cards in storage
-		for _, thiscard := range player.Tableau.Storage {
-			if thiscard != nil {
-				availableCards++
+		// Add in the cards in storage, if they may be spent
+		if player.SpendStorage {
+			for _, thiscard := range player.Tableau.Storage {
+				if thiscard != nil {
+					availableCards++
+				}
 			}
+		} else if pos.From == FromStorage {
+			// the card itself isn't in the hand
+			availableCards++
 		}
 		if discountedCost > availableCards {
 			reason = "You can't afford it"
@@ -173,11 +159,12 @@ func cardIsBuildable(pos Pos, thiscard card.Card, player Player) (buildable bool
 }
 
 
-func (currentPlayer Player) clearScreen(state string) {
-	cmd := exec.Command("clear")
-    cmd.Stdout = os.Stdout
-    cmd.Run()
-    fmt.Printf(state)
+// the verbs a human can start a command with for each menu, besides pass
+var menuVerbs = map[string][]string{
+	"build":   {"build", "upgrade"},
+	"discard": {"discard"},
+	"trash":   {"trash"},
+	"store":   {"store"},
 }
 
 
@@ -187,20 +174,32 @@ func (currentPlayer Player) clearScreen(state string) {
 func (player Player) humanChooses(
 	verb string,
 	allowedFrom map[int] bool, 
-	stock *card.Hand, 
-	discardPile *card.Hand, 
 	cardIsValid cardTest, 
 	passAllowed bool,
 	selectCount int) (positions []Pos) {
 
 	choiceId := 0 // this is the number the human will key in to make their choice
 	choice := make(map[int]Pos) // keep track of what each choice points to
+	// they can also type a command, with a verb that goes with the menu and the cards by name
+	verbs := make(map[string]int)
+	for _, v := range menuVerbs[verb] {
+		verbs[v] = -1
+	}
+	if passAllowed {
+		verbs["pass"] = 0
+	}
+	commands := newPrompt(verbs)
+	commands.nine = selectCount > 1
 
-	player.clearScreen(player.State)
-	fmt.Println("-=* ", strings.ToUpper(verb), " *=-")
+	// the choices are listed in the panel under the table
+	menu := Menu{Title: verb, Count: selectCount, Question: fmt.Sprintf("Choose a card to %s:", verb)}
+	item := func(label string, reason string) {
+		menu.Items = append(menu.Items, MenuItem{Number: choiceId, Label: label, Reason: reason})
+	}
 	if passAllowed {
-		fmt.Println("0 . no", verb)
+		item("no "+verb, "")
 		choice[choiceId] = Pos{NoCard, 0}
+		commands.add(choiceId)
 	}
 
 	choiceId++ // only pass is ever 0, so if no pass, we still move up by one
@@ -214,17 +213,19 @@ func (player Player) humanChooses(
 
 		if space == FromDiscard {
 			// only if there's a card available on the discard
-			if discardPile.PullPos == 0 { // TODO: test this case
+			thiscard := player.DiscardTop()
+			if thiscard == nil {
 				continue
 			}
-			thiscard := discardPile.Cards[discardPile.PullPos]
-			fmt.Printf("%d. DISCARD %s: %s\n", choiceId, thiscard, thiscard.Rule)
+			item(fmt.Sprintf("DISCARD %s: %s", thiscard, thiscard.Rule), "")
 			choice[choiceId] = Pos{space, 0}
+			commands.add(choiceId, append([]string{"discard"}, cardNames(thiscard)...)...)
 			continue
 
 		} else if space == FromStock {
-			fmt.Printf("%d. STOCK\n", choiceId)
+			item("STOCK", "")
 			choice[choiceId] = Pos{space, 0}
+			commands.add(choiceId, "stock")
 			choiceId++
 			continue // to to the next space
 
@@ -242,81 +243,85 @@ func (player Player) humanChooses(
 			}
 			isValid, reason := cardIsValid(Pos{space, id}, *thiscard, player)
 			if (!isValid) {
-				fmt.Printf("   %s%s (%s)\n", location, thiscard, reason)
+				item(location+thiscard.String(), reason)
 				continue
 			}
-			fmt.Printf("%d. %s%s: %s\n", choiceId, location, thiscard, thiscard.Rule)
+			item(fmt.Sprintf("%s%s: %s", location, thiscard, thiscard.Rule), "")
 			choice[choiceId] = Pos{space, id}
+			commands.add(choiceId, cardNames(thiscard)...)
 			choiceId++
 		}
 	}
 
 	// if they have more than one choice, offer them a redo option
 	if selectCount > 1 {
-		fmt.Printf("9. I messed up\nChoose %d\n", selectCount)
+		choiceId = 9
+		item("I messed up", "")
 	}
+	player.UI.Render(player.PlayerView, player.news())
 
 	positions = make([]Pos, selectCount)
 
-	if choiceId == 1 {
+	if len(choice) == 0 || len(choice) == 1 && passAllowed {
 		// they don't really have a choice, just select 0: no card for them
 		return
 	}
-	if choiceId == 2 && selectCount == 1 && !passAllowed {
+	if len(choice) == 1 && selectCount == 1 {
 		// there's only one choice, so just make it for them
 		positions[0] = choice[1]
+		for _, it := range menu.Items {
+			if it.Number == 1 && it.Reason == "" {
+				player.UI.Notify(fmt.Sprintf("The only choice to %s is %s", verb, it.Label))
+			}
+		}
 		return
 	}
-	// this outer "for" is to allow the user to restart their choice
-	for ;; {
-		i := 0
-		tempChoice := make(map[int]Pos)
-		// make a copy of the map, so we can remove elements as we go
-		for k, v := range choice {
-			tempChoice[k] = v
-		}
-		for ; i < selectCount; i++ {
-			pos, input := queryPos(verb, tempChoice)
-			if input == 9 {
-				fmt.Printf("Start over selecting your cards\n")
-				break; // if they get here, start over
+	player.analyse()
+	// they may pick all the cards in one go, or one at a time, and start over with undo
+	all := make(map[int][]string)
+	for id, names := range commands.answers {
+		all[id] = names
+	}
+	picked := 0
+	read := func() (string, error) { return player.UI.Choose(menu, commands.words) }
+	for picked < selectCount {
+		question := menu.Question
+		if picked > 0 {
+			question = fmt.Sprintf("Choose %d more to %s:", selectCount-picked, verb)
+		}
+		r := player.ask(commands, question, read)
+		read = func() (string, error) { return player.UI.Prompt(question, commands.words) }
+		if r.undo {
+			if picked == 0 {
+				player.UI.Notify("There's nothing to undo")
+				continue
 			}
-			positions[i] = pos
+			player.UI.Notify("Start over selecting your cards")
+			for id, names := range all {
+				commands.answers[id] = names
+			}
+			picked = 0
+			continue
+		}
+		if len(r.numbers) > selectCount-picked {
+			player.UI.Notify(fmt.Sprintf("That's %d cards, and there are only %d to choose", len(r.numbers), selectCount-picked))
+			continue
+		}
+		for _, input := range r.numbers {
+			pos := choice[input]
+			positions[picked] = pos
+			picked++
 			if pos.From == NoCard {
 				return
 			}
 			// remove that choice from the list so they can't select it again
-			delete(tempChoice, input)
-		}
-
-		if i == selectCount {
-			break; // if we got here they finished their selection
+			delete(commands.answers, input)
 		}
 	}
 	return
 }
 
 
-func queryPos(verb string, choice map[int]Pos) (Pos, int) {
-	// loop until they select a valid response
-	for ;; {
-		fmt.Printf("Choose a card to %s:\n", verb)
-		var input int
-		fmt.Scan(&input)
-
-		if input == 9 {
-			return Pos{}, 9
-		}
-
-		_, ok := choice[input] // check if the value given is in the choices
-		if !ok {
-			continue
-		}
-		return choice[input], input
-	}
-}
-
-
 func (player Player) ChooseDiscards(protected Pos, cost int, phase int) (discards []Pos) {
 	if player.Human {
 		return player.HumanChooseDiscards(protected, cost)
@@ -353,9 +358,7 @@ func (player Player) HumanChooseDiscards(protected Pos, cost int) (discards []Po
 	}
 	return player.humanChooses(
 		"discard",
-		legalDiscardFrom, 
-		nil, // stock
-		nil, // discardPile
+		player.spendFrom(),
 		excludeProtected,
 		false, // pass allowed
 		cost, // selectCount
@@ -367,9 +370,7 @@ func (currentPlayer Player) ChooseTrash(phase int) (trashPos []Pos) {
 	if currentPlayer.Human {
 		return currentPlayer.humanChooses(
 			"trash",
-			legalTrashFrom,
-			nil, // stock
-			nil, // discardPile
+			currentPlayer.spendFrom(),
 			everythingIsAwesome,
 			true, // pass allowed
 			1, // selectCount
@@ -388,7 +389,7 @@ func (currentPlayer Player) ChooseTrash(phase int) (trashPos []Pos) {
 		// or if your lowest value card is still valuable
 		// outside of the hand limit, go ahead and trash
 		if currentPlayer.Hand.Count <= currentPlayer.Hand.Limit { 
-			if (currentPlayer.Tableau.DrawBonus == 0) || (value > 31) { // values are from 0-63
+			if (currentPlayer.Tableau.DrawBonus == 0) || (value > currentPlayer.traits().TrashAt) { // values are from 0-63
 				oneTrash.From = NoCard
 				trashPos[cardsTrashed] = oneTrash
 				break
@@ -408,7 +409,7 @@ func (currentPlayer Player) TrashCards(Poses []Pos, trash *card.Hand) (count int
 		if pos.From == NoCard {
 			break;
 	    }
-		fmt.Printf("Current player trashes %s\n", currentPlayer.CardByPos(pos))
+		log(1, fmt.Sprintf("Current player trashes %s", currentPlayer.CardByPos(pos)))
 		currentPlayer.Spend(pos, trash)
 		count++
 	}
@@ -426,6 +427,7 @@ func (player Player) LowestValueCard(phase int, excludeList [][]bool) (pos Pos,
 		excludeList = make([][]bool, 3) // there are 3 spaces where this is valid: nothing, hand, and storage
 	}
 
+	value = 64 // 0 to 63, compared across the hand and storage
 	for space := 1; space <= 2; space++ {
 		var cardrange []*card.Card
 		if space == FromHand {
@@ -434,12 +436,14 @@ func (player Player) LowestValueCard(phase int, excludeList [][]bool) (pos Pos,
 				excludeList[space] = make([]bool, player.Hand.Max)
 			}
 		} else if space == FromStorage {
+			if !player.SpendStorage {
+				continue
+			}
 			cardrange = player.Tableau.Storage
 			if excludeList[space] == nil {
 				excludeList[space] = make([]bool, 2) // there are a max of 2 storage in the current rules
 			}
 		}
-		value := 64 // 0 to 63
 		for id, thiscard := range cardrange {
 			if (thiscard == nil) || excludeList[space][id] {
 				continue
@@ -483,8 +487,8 @@ func (player Player) HighestValueCard(phase int, excludeList []bool) (highPos in
 
 func (player Player) CardValue(thiscard *card.Card, phase int) (value int) {
 	// is the card already in the Tableau, or less than a value in the Tableau?
-	// the hard part would be figuring the odds that a card may be taken by an 
-	// opposing soldier
+	// the hard part is figuring the odds that a card may be taken by an
+	// opposing soldier, which threatAdjust estimates
 	modifier := 0
 	if player.Tableau.Stack[thiscard.Kind] != nil {
 	    posCost := player.TopCard(thiscard.Kind).Cost
@@ -497,11 +501,25 @@ func (player Player) CardValue(thiscard *card.Card, phase int) (value int) {
 		} else if posCost < (thiscard.Cost - 1) {
 			// in this case, our card isn't playable yet, but may be in the future
 			modifier = -10
+			if !player.upgradePossible(thiscard) {
+				// the cards we'd need to get there are all gone
+				value = 0
+				return
+			}
+		} else {
+			modifier = player.traits().UpgradeBonus
 		}
 	} 
 	// if the card is still playable get the base value of the card, which depends on the player's strategy
 	value = player.Strategy[phase][thiscard.Kind][thiscard.Cost]
-	value += modifier
+	value += modifier + player.traits().KindBonus[thiscard.Kind]
+	value = player.threatAdjust(thiscard, value, phase)
+	value = player.endgameAdjust(thiscard, value)
+	if value > 63 {
+		value = 63
+	} else if value < 0 {
+		value = 0
+	}
 	return
 }
 
@@ -529,7 +547,7 @@ func (player *Player) Build(buildPos Pos, discards []Pos, discardPile *card.Hand
 	for i := 0; i < 4; i++ {
 		if upgrade {
 			// if they already have a power, subtract the old power before you add the new one
-			(*player).Tableau.Discounts[i] -= (*player).TopCard(kind).CostModifier[i] //(*player).Tableau.Stack[kind].Cards[pullPos].CostModifier[i]
+			(*player).Tableau.Discounts[i] -= (*player).Tableau.Stack[kind].Cards[pullPos].CostModifier[i]
 		}
 		(*player).Tableau.Discounts[i] += buildCard.CostModifier[i]
 	}
@@ -564,32 +582,52 @@ func (player *Player) Build(buildPos Pos, discards []Pos, discardPile *card.Hand
 
 
 // TODO: this could be done better
-func (player *Player) ChooseStore(stock *card.Hand, discardPile *card.Hand, phase int) (chosen *card.Card) {
-	var pos Pos
+// ChooseStore picks where to fill an open storage spot from: the stock, the discard or the hand.
+// From is NoCard if there's nothing to store.
+func (player *Player) ChooseStore(phase int) (pos Pos) {
 	if (*player).Human {
-		pos = (*player).humanChooseStore(stock, discardPile)
-	} else {
-		// if the best card in the discard or hand is less than 31, just draw from the stock
-		discardValue := player.CardValue(discardPile.Cards[discardPile.PullPos], phase) 
-		handPos, handValue := player.HighestValueCard(phase, nil)
-		if (discardValue < 32) && (handValue < 32) {
-			// draw from the stock
-			pos = Pos{FromStock, 0}
-			fmt.Sprintf("Player fills storage from Stock: %s", (*stock).Cards[(*stock).PullPos])
-		} else if (discardValue < handValue) {
-			// draw from the hand
-			pos = Pos{FromHand, handPos}
-			fmt.Sprintf("Player fills storage from Hand: %s", (*player).Hand.Cards[handPos])
-		} else {
-			pos = Pos{FromDiscard, 0}
-			fmt.Sprintf("Player fills storage from Discard: %s", discardPile.Cards[discardPile.PullPos])
-		}
+		return (*player).humanChooseStore()
+	}
+	// if the best card in the discard or hand isn't worth storing, just draw from the stock
+	storeAt := player.traits().StoreAt
+	if expected, ok := player.ExpectedDrawValue(phase); ok {
+		// the stock is a gamble, worth what we expect to find there
+		storeAt = expected + storeAt - Heuristic.StoreAt
+		if storeAt < 0 {
+			// below 0 nothing would be worth storing, not even from the stock
+			storeAt = 0
+		}
+	}
+	discardValue := -1 // an empty discard pile can't be chosen
+	top := player.DiscardTop()
+	if top != nil {
+		discardValue = player.CardValue(top, phase)
+	}
+	handPos, handValue := player.HighestValueCard(phase, nil)
+	if handPos == -1 {
+		handValue = -1
+	}
+	if (discardValue < storeAt) && (handValue < storeAt) && (player.Stock() > 0) {
+		// draw from the stock
+		pos = Pos{FromStock, 0}
+		log(2, "Player fills storage from Stock")
+	} else if (discardValue < handValue) {
+		// draw from the hand
+		pos = Pos{FromHand, handPos}
+		log(2, fmt.Sprintf("Player fills storage from Hand: %s", (*player).Hand.Cards[handPos]))
+	} else if discardValue > -1 {
+		pos = Pos{FromDiscard, 0}
+		log(2, fmt.Sprintf("Player fills storage from Discard: %s", top))
 	}
+	return
+}
 
+
+// TakeToStore removes the card chosen for storage from where it was, and passes it back
+func (player *Player) TakeToStore(pos Pos, stock *card.Hand, discardPile *card.Hand) (chosen *card.Card) {
 	if pos.From == FromStock {
 		chosen = (*stock).Cards[(*stock).PullPos] // pull from the current pull position in the stock
 		(*stock).PullPos--
-//		return player.CardByPos(pos)
 	} else if pos.From == FromDiscard {
 		chosen = (*discardPile).Cards[(*discardPile).PullPos]
 		(*discardPile).Cards[(*discardPile).PullPos] = nil
@@ -603,10 +641,9 @@ func (player *Player) ChooseStore(stock *card.Hand, discardPile *card.Hand, phas
 
 
 // TODO: pick 2 if that's the option
-// Choose from the hand, stock and discard pile, remove the card from the source, and pass it back
-func (player *Player) humanChooseStore(stock *card.Hand, discardPile *card.Hand) (pos Pos) {
-	fmt.Println("You may store a card.  Please choose:")
-	choices := (*player).humanChooses("store", legalStoreFrom, stock, discardPile, everythingIsAwesome, false, 1)
+// Choose from the hand, stock and discard pile
+func (player *Player) humanChooseStore() (pos Pos) {
+	choices := (*player).humanChooses("store", legalStoreFrom, everythingIsAwesome, false, 1)
 	pos = choices[0] // you can only choose 1
 	return
 }
@@ -643,52 +680,53 @@ func (player Player) CardByPos(pos Pos) (returnCard *card.Card) {
 }
 
 
-func (currentPlayer Player) humanChooseAttack(opponent Player) (steal int) {
+func (currentPlayer Player) humanChooseAttack() (steal int) {
+	opponent := currentPlayer.Opponent
 	steal = -1
 	// if the opponent has a defensive building, you have to do that
 	attackPower := currentPlayer.TopCard(card.Soldiers).Cost + currentPlayer.Tableau.AttackBonus
-	if opponent.Tableau.Stack[card.Defensive] != nil {
+	if opponent.Stack[card.Defensive] != nil {
 		// make sure they can handle the defensive building
-		if attackPower >= opponent.TopCard(card.Defensive).Cost {
+		if attackPower >= opponent.Top(card.Defensive).Cost {
 			// you can take their defensive card
-			for ;; { // loop until you get a valid response
-				fmt.Printf("Would you like to use your soldier to take your opponent's %s (y/n)?\n", opponent.TopCard(card.Defensive).Name)
-				var input string
-				fmt.Scan(&input)
-				if input == "y" {
-					steal = card.Defensive
-					return
-				} else if input == "n" {
-					return
-				}
+			currentPlayer.UI.Render(currentPlayer.PlayerView, currentPlayer.news())
+			currentPlayer.analyse()
+			question := fmt.Sprintf("Would you like to use your soldier to take your opponent's %s (y/n)?", opponent.Top(card.Defensive).Name)
+			if currentPlayer.askYes("attack", question, yesNo(map[string]int{"attack": 1, "pass": 0}, cardNames(opponent.Top(card.Defensive))...)) {
+				steal = card.Defensive
 			}
 		}
 		return
 	}
 	found := false
 	choice := make(map[int] int) // keep track of what each choice points to
-	options := "--ATTACK--\n0. No attack\n"
+	menu := Menu{Title: "attack", Count: 1, Question: "Choose a card to take from your opponent:"}
+	menu.Items = append(menu.Items, MenuItem{Number: 0, Label: "No attack"})
 	choice[0] = -1
+	commands := newPrompt(map[string]int{"attack": -1, "pass": 0})
+	commands.add(0)
 	choiceId := 1 // this is the number the human will key in to make their choice
 	for kind := 0; kind <= 9; kind++ {
-		if opponent.Tableau.Stack[kind] != nil && attackPower >= opponent.TopCard(kind).Cost {
+		if opponent.Stack[kind] != nil && attackPower >= opponent.Top(kind).Cost {
 			found = true
-			options += fmt.Sprintf("%d. %s\n", choiceId, opponent.TopCard(kind))
+			menu.Items = append(menu.Items, MenuItem{Number: choiceId, Label: opponent.Top(kind).String()})
 			choice[choiceId] = kind
+			commands.add(choiceId, cardNames(opponent.Top(kind))...)
 			choiceId++
 		}
 	}
 	
 	if found {
-		fmt.Printf(options)
+		currentPlayer.UI.Render(currentPlayer.PlayerView, currentPlayer.news())
+		currentPlayer.analyse()
+		read := func() (string, error) { return currentPlayer.UI.Choose(menu, commands.words) }
 		for ;; { // loop until you get a valid response
-			fmt.Printf("Choose a card to take from your opponent:\n")
-			var input int
-			fmt.Scan(&input)
-			_, ok := choice[input] // check if the value given is in the choices
-			if ok {
-				return choice[input]
+			r := currentPlayer.ask(commands, menu.Question, read)
+			if len(r.numbers) == 1 {
+				return choice[r.numbers[0]]
 			}
+			currentPlayer.UI.Notify("Choose just the one card")
+			read = func() (string, error) { return currentPlayer.UI.Prompt(menu.Question, commands.words) }
 		}
 	}
 	return
@@ -696,20 +734,15 @@ func (currentPlayer Player) humanChooseAttack(opponent Player) (steal int) {
 
 
 func (currentPlayer Player) HumanWantsRedraw() (bool) {
-	for ;; { // loop until you get a valid response
-		fmt.Printf("Would you like to trash your hand and redraw %d cards (y/n)?:\n", currentPlayer.Hand.Count)
-		var input string
-		fmt.Scan(&input)
-		if input == "y" {
-			return true
-		} else if input == "n" {
-			return false
-		}
-	}
+	currentPlayer.UI.Render(currentPlayer.PlayerView, currentPlayer.news())
+	currentPlayer.analyse()
+	question := fmt.Sprintf("Would you like to trash your hand and redraw %d cards (y/n)?:", currentPlayer.Hand.Count)
+	return currentPlayer.askYes("redraw", question, yesNo(map[string]int{"redraw": 1, "keep": 0}))
 }
 
 
-func (currentPlayer Player) ChooseAttack(opponent Player, phase int) (steal int) {
+func (currentPlayer Player) ChooseAttack(phase int) (steal int) {
+	opponent := currentPlayer.Opponent
 	steal = -1
 	// for now, I'll just attack as soon as I can, but I will try to take the best card
 	if currentPlayer.Tableau.Stack[card.Soldiers] == nil {
@@ -717,15 +750,29 @@ func (currentPlayer Player) ChooseAttack(opponent Player, phase int) (steal int)
 	}
 
 	if currentPlayer.Human {
-		return currentPlayer.humanChooseAttack(opponent)
+		return currentPlayer.humanChooseAttack()
+	}
+
+	traits := currentPlayer.traits()
+	// late in the game, when the opponent is winning, any attack is better than none
+	attackAt := traits.AttackAt
+	blocking := currentPlayer.blocking()
+	if blocking {
+		attackAt = 0
+	}
+	// some players would rather keep their soldiers at home while the opponent could attack them
+	if traits.KeepsSoldiers && !blocking && (opponent.Stack[card.Military] != nil || opponent.Stack[card.Soldiers] != nil) {
+		return
 	}
 
 	// if the opponent has a defensive building, you have to do that
-	if opponent.Tableau.Stack[card.Defensive] != nil {
+	if opponent.Stack[card.Defensive] != nil {
 		// make sure they can handle the defensive building
-		if (currentPlayer.TopCard(card.Soldiers).Cost + currentPlayer.Tableau.AttackBonus) >= opponent.TopCard(card.Defensive).Cost {
-			// you can take their defensive card
-			steal = card.Defensive
+		if (currentPlayer.TopCard(card.Soldiers).Cost + currentPlayer.Tableau.AttackBonus) >= opponent.Top(card.Defensive).Cost {
+			// you can take their defensive card, if it's worth the soldier
+			if currentPlayer.AttackValue(opponent.Top(card.Defensive), phase) >= attackAt {
+				steal = card.Defensive
+			}
 		}
 		return
 	}
@@ -734,63 +781,81 @@ func (currentPlayer Player) ChooseAttack(opponent Player, phase int) (steal int)
 	value := -1
 	bestKind := -1
 	for kind := 0; kind <= 9; kind++ {
-		if opponent.Tableau.Stack[kind] != nil {
-			if (currentPlayer.TopCard(card.Soldiers).Cost + currentPlayer.Tableau.AttackBonus) >= opponent.TopCard(kind).Cost {
+		if opponent.Stack[kind] != nil {
+			if (currentPlayer.TopCard(card.Soldiers).Cost + currentPlayer.Tableau.AttackBonus) >= opponent.Top(kind).Cost {
 				// note, it's how this player values the card, not the opponent
-				if (value == -1) || (currentPlayer.CardValue(opponent.TopCard(kind), phase) > value) {
-					value = currentPlayer.CardValue(opponent.TopCard(kind), phase)
+				if (value == -1) || (currentPlayer.AttackValue(opponent.Top(kind), phase) > value) {
+					value = currentPlayer.AttackValue(opponent.Top(kind), phase)
 					bestKind = kind
 				}
 			}
 		}
 	}
-	if bestKind != -1 {
+	if bestKind != -1 && value >= attackAt {
 		steal = bestKind
 	}
 	return
 }
 
 
-func (currentPlayer *Player) Draw(discardPile *card.Hand, stock *card.Hand, phase int) {
-    if (*currentPlayer).Tableau.DrawFromDiscardPower < 1 {
-	    stock.RandomPull(2, (*currentPlayer).Hand) // this will only pull up to the hand limit
-	    return
+// The opponent's soldier is coming for one of your cards.  Using your own soldier takes its value off
+// the attack, which may leave the attacker too weak to take the card.  The defending soldier is used up.
+func (currentPlayer Player) ChooseDefend(attacker *card.Card, attackPower int, target int, phase int) bool {
+	defender := currentPlayer.TopCard(card.Soldiers)
+	if defender == nil || target == card.Soldiers {
+		return false
 	}
-	// here we should use "drawFromDiscardPower" when it's greater than one
-	drawCount := (*currentPlayer).Hand.Max - (*currentPlayer).Hand.Count
-	if drawCount == 0 {
-		return
+	if currentPlayer.Human {
+		return currentPlayer.humanChooseDefend(attacker, attackPower, target)
 	}
-	if drawCount > 2 { // you can't draw more than 2
-		drawCount = 2
-	}
-	// loop through the draws you have
-	for ; drawCount > 0; drawCount-- {
-		if discardPile.PullPos == -1 { // the discard pile is empty, must pull from stock
-        	stock.RandomPull(1, (*currentPlayer).Hand)
-		} else if (*currentPlayer).Human {
-			for ;; { // loop until you get a valid response
-				fmt.Printf("Would you like to draw from the discard '%s' (y/n)?\n", discardPile.Cards[discardPile.PullPos])
-				var input string
-				fmt.Scan(&input)
-				if input == "y" {
-		   			discardPile.TopPull(1, (*currentPlayer).Hand)
-		   			break;
-				} else if input == "n" {
-					// pull the remaining cards from the stock
-		        	stock.RandomPull(drawCount, (*currentPlayer).Hand)
-		        	return;
-				}
-			}
+	// there's no point defending if the attack gets through anyway
+	if attackPower - defender.Cost >= currentPlayer.TopCard(target).Cost {
+		return false
+	}
+	return currentPlayer.DefendValue(target, phase) >= currentPlayer.traits().DefendAt
+}
+
 
-       	} else if (*currentPlayer).CardValue(discardPile.Cards[discardPile.PullPos], phase) > 31 {
-   			fmt.Printf("Player draws %s from the discard\n", discardPile.Cards[discardPile.PullPos])
-   			discardPile.TopPull(1, (*currentPlayer).Hand)
-        } else {
-        	stock.RandomPull(1, (*currentPlayer).Hand)
-        }
-    }
+// DefendValue is how much we want to keep our card of the kind under attack.  It's our own card, so take its
+// value from the strategy rather than CardValue, which values what we'd build.
+func (currentPlayer Player) DefendValue(target int, phase int) int {
+	targetCard := currentPlayer.TopCard(target)
+	value := currentPlayer.Strategy[phase][target][targetCard.Cost] + currentPlayer.traits().KindBonus[target]
+	return value + int(currentPlayer.Urgency() * float64(16*targetCard.VictoryPoints))
+}
 
+
+func (currentPlayer Player) humanChooseDefend(attacker *card.Card, attackPower int, target int) bool {
+	defender := currentPlayer.TopCard(card.Soldiers)
+	currentPlayer.UI.Render(currentPlayer.PlayerView, currentPlayer.news())
+	currentPlayer.analyse()
+	currentPlayer.UI.Notify(fmt.Sprintf("Your opponent's %s (attack %d) is coming for your %s.", attacker, attackPower, currentPlayer.TopCard(target)))
+	question := fmt.Sprintf("Would you like to use your %s to defend (y/n)?", defender)
+	return currentPlayer.askYes("defend", question, yesNo(map[string]int{"defend": 1, "pass": 0}, defender.Name))
+}
+
+
+// WantsDiscard says whether the player, holding a Market, would rather draw the top of the discard than
+// take their chances on the stock
+func (currentPlayer Player) WantsDiscard(top *card.Card, phase int) bool {
+	if currentPlayer.Human {
+		currentPlayer.UI.Render(currentPlayer.PlayerView, currentPlayer.news())
+		currentPlayer.analyse()
+		question := fmt.Sprintf("Would you like to draw from the discard '%s' (y/n)?", top)
+		commands := yesNo(map[string]int{"draw": -1}, "discard", top.Name)
+		commands.add(0, "stock")
+		return currentPlayer.askYes("draw", question, commands)
+	}
+	// the stock is worth what we expect to find there, if we've been counting cards
+	drawAt := currentPlayer.traits().DrawAt
+	if expected, ok := currentPlayer.ExpectedDrawValue(phase); ok {
+		drawAt = expected + drawAt - Heuristic.DrawAt
+	}
+	if currentPlayer.CardValue(top, phase) > drawAt {
+		log(1, fmt.Sprintf("Player draws %s from the discard", top))
+		return true
+	}
+	return false
 }
 
 
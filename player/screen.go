@@ -0,0 +1,213 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+The screen is what a human sees at a Console at every decision: the whole table drawn from their view, with
+both tableaus side by side, the storage, the piles and their own hand, and under it a panel for the question
+in front of them.  It's drawn with ANSI escape codes, so it needs a terminal that takes them, which is most
+of them.  A Plain console leaves the codes out.
+*/
+
+// ANSI escape codes
+const (
+	home      = "\x1b[H"
+	clearDown = "\x1b[J"
+	clearAll  = "\x1b[2J\x1b[3J" // the screen and what's scrolled off it
+	reset     = "\x1b[0m"
+	bold      = "\x1b[1m"
+	faint     = "\x1b[2m"
+	inverse   = "\x1b[7m"
+)
+
+// each material has its own color: wood is brown (as near as a terminal gets), metal blue, stone grey
+var materialColor = map[int]string{
+	card.Wood:    "\x1b[33m",
+	card.Metal:   "\x1b[36m",
+	card.Stone:   "\x1b[37m",
+	card.Soldier: "\x1b[31m",
+}
+
+const (
+	screenWidth = 78
+	columnWidth = 36 // each tableau's half of the screen
+	newsLines   = 4  // the most recent news kept on the screen, so the panel starts on the same line every time
+	screenLines = 60 // more than the table and a panel, to scroll them out of sight
+)
+
+// paint wraps the text in the escape codes, unless the console is plain
+func (c *Console) paint(text string, codes ...string) string {
+	if c.Plain || len(codes) == 0 {
+		return text
+	}
+	return strings.Join(codes, "") + text + reset
+}
+
+// pad fits the text to the width, cutting it off if it's too long
+func pad(text string, width int) string {
+	if len(text) > width {
+		return text[:width]
+	}
+	return text + strings.Repeat(" ", width-len(text))
+}
+
+// cardLabel is the short name of a card on the screen, like "Pig Farm 2"
+func cardLabel(c *card.Card) string {
+	return fmt.Sprintf("%s %d", c.Name, c.Cost)
+}
+
+// paintCard fits a card's label to the width in the color of its material, or a dot if there's no card
+func (c *Console) paintCard(shown *card.Card, width int) string {
+	if shown == nil {
+		return c.paint("·", faint) + strings.Repeat(" ", width-1)
+	}
+	return c.paint(pad(cardLabel(shown), width), materialColor[shown.Material])
+}
+
+// pileTop describes a face up pile, its count and the card on top
+func (c *Console) pileTop(name string, pile *card.Hand) string {
+	if pile.PullPos < 0 {
+		return fmt.Sprintf("%s empty", name)
+	}
+	top := pile.Cards[pile.PullPos]
+	return fmt.Sprintf("%s %d: %s", name, pile.PullPos+1, c.paint(cardLabel(top), materialColor[top.Material]))
+}
+
+func tableauVP(t *card.Tableau) (vp int) {
+	for kind := 0; kind <= 9; kind++ {
+		if top := t.Top(kind); top != nil {
+			vp += top.VictoryPoints
+		}
+	}
+	return
+}
+
+// powers sums up what a tableau gives, like "wood -1, +1 build"
+func powers(t *card.Tableau) string {
+	var list []string
+	for material, discount := range t.Discounts {
+		if discount != 0 {
+			list = append(list, fmt.Sprintf("%s %d", card.MaterialName(material), discount))
+		}
+	}
+	if t.BuildBonus > 0 {
+		list = append(list, fmt.Sprintf("+%d build", t.BuildBonus))
+	}
+	if t.AttackBonus > 0 {
+		list = append(list, fmt.Sprintf("+%d attack", t.AttackBonus))
+	}
+	if t.TrashBonus > 0 {
+		list = append(list, fmt.Sprintf("trash %d", t.TrashBonus))
+	}
+	if t.DrawFromDiscardPower > 0 {
+		list = append(list, "draw discard")
+	}
+	if len(list) == 0 {
+		return "none"
+	}
+	return strings.Join(list, ", ")
+}
+
+// storageRow shows the two storage spots, empty or not
+func (c *Console) storageRow(t *card.Tableau) string {
+	row := ""
+	for _, stored := range t.Storage {
+		if stored == nil {
+			row += c.paint("[   ]", faint) + " "
+			continue
+		}
+		row += "[" + c.paint(cardLabel(stored), materialColor[stored.Material]) + "] "
+	}
+	return row
+}
+
+// screen draws the table as the seat sees it, ready for the panel to go under it
+func (c *Console) screen(view *PlayerView, news string) string {
+	var b strings.Builder
+	line := func(format string, a ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", a...)
+	}
+	rule := c.paint(strings.Repeat("─", screenWidth), faint)
+	turn := 0
+	if view.Clock != nil {
+		turn = view.Clock.Turn
+	}
+	line("%s  turn %d    stock %d    %s    %s", c.paint(" WARWICK ", bold, inverse), turn, view.Stock(),
+		c.pileTop("discard", view.DiscardPile), c.pileTop("trash", view.Trash))
+	line("%s", rule)
+
+	// the two tableaus side by side, one row for each kind of card
+	mine := fmt.Sprintf("YOUR TABLEAU  %d VP", tableauVP(view.Tableau))
+	theirs := fmt.Sprintf("OPPONENT  %d VP, %d in hand", tableauVP(view.Opponent), view.OpponentHand())
+	line(" %s  %s", c.paint(pad(mine, columnWidth), bold), c.paint(theirs, bold))
+	for kind := 0; kind <= 9; kind++ {
+		name := pad(card.KindName(kind), 14)
+		line(" %s%s  %s%s", c.paint(name, faint), c.paintCard(view.Tableau.Top(kind), columnWidth-14),
+			c.paint(name, faint), c.paintCard(view.Opponent.Top(kind), columnWidth-14))
+	}
+	// storage holds two cards, which may be longer than the column, so they get a line each side
+	line(" %s%s", c.paint(pad("storage", 14), faint), c.storageRow(view.Tableau))
+	line(" %s%s", c.paint(pad("their storage", 14), faint), c.storageRow(view.Opponent))
+	line(" %s%s  %s%s", c.paint(pad("powers", 14), faint), pad(powers(view.Tableau), columnWidth-14),
+		c.paint(pad("powers", 14), faint), pad(powers(view.Opponent), columnWidth-14))
+	line("%s", rule)
+
+	// the hand, four cards to a line and always two lines
+	var held []string
+	for _, h := range view.Hand.Cards {
+		if h != nil {
+			held = append(held, c.paint(pad(cardLabel(h), 15), materialColor[h.Material]))
+		}
+	}
+	for row := 0; row < 2; row++ {
+		label := "          "
+		if row == 0 {
+			label = c.paint("YOUR HAND ", bold)
+		}
+		from, to := row*4, row*4+4
+		if from > len(held) {
+			from = len(held)
+		}
+		if to > len(held) {
+			to = len(held)
+		}
+		line(" %s%s", label, strings.Join(held[from:to], " "))
+	}
+	line("%s", rule)
+
+	// the last few things that happened
+	recent := strings.Split(strings.TrimRight(news, "\n"), "\n")
+	if len(recent) > newsLines {
+		recent = recent[len(recent)-newsLines:]
+	}
+	for i := 0; i < newsLines; i++ {
+		if i < len(recent) {
+			line(" %s", pad(recent[i], screenWidth-1))
+		} else {
+			line("")
+		}
+	}
+	line("%s", rule)
+	return b.String()
+}
+
+// show clears the terminal and draws the table, with the panel under it.  The table is the same height every
+// time, so the panel is always in the same place.
+func (c *Console) show(panel string) {
+	panel += c.pending
+	c.pending, c.fresh = "", false
+	if c.view == nil {
+		fmt.Fprint(c.Out, panel)
+		return
+	}
+	if c.Plain {
+		fmt.Fprint(c.Out, "\n"+c.screen(c.view, c.news)+panel)
+		return
+	}
+	fmt.Fprint(c.Out, home+clearDown+c.screen(c.view, c.news)+panel)
+}
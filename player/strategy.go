@@ -0,0 +1,94 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chrislunt/warwick/card"
+)
+
+// The phases of the game a strategy can value cards differently in
+var phaseNames = []string{"early", "middle", "late"}
+
+// DefaultStrategy values every card by its cost, the same in every phase.
+// Values run from 0 to 63, so a level 4 card is worth 63.
+func DefaultStrategy() (strategy [][][]int) {
+	// instead of 1 value per turn, do 3 columns for beginning, middle and end.
+	// Value can be set by cost to start with.  Value may be adjusted by changes in cost.
+	// value could be affected at time of spend by what may be discarded as well.
+	strategy = make([][][]int, 3)
+	for phase := 0; phase <= 2; phase++ {
+		strategy[phase] = make([][]int, 10)
+		for kind := 0; kind <= 9; kind++ {
+			strategy[phase][kind] = make([]int, 5)
+			for cost := 1; cost <= 4; cost++ {
+				strategy[phase][kind][cost] = cost*16 - 1
+			}
+		}
+	}
+	return
+}
+
+/*
+A strategy file is JSON naming the phase, then the card kind, then the value of the level 1 to 4 cards.
+Anything left out keeps its default value:
+
+	{
+		"name": "churchy",
+		"description": "builds up Civic buildings early",
+		"phases": {
+			"early": {"Civic": [40, 50, 60, 63]},
+			"late":  {"Civic": [20, 40, 60, 63], "Soldiers": [0, 0, 10, 20]}
+		}
+	}
+*/
+type strategyFile struct {
+	Name        string
+	Description string
+	Phases      map[string]map[string][]int
+}
+
+// LoadStrategy reads a strategy file, returning its name and the strategy table
+func LoadStrategy(path string) (name string, strategy [][][]int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var file strategyFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return "", nil, fmt.Errorf("%s: %v", path, err)
+	}
+	name = file.Name
+	if name == "" {
+		name = path
+	}
+	strategy = DefaultStrategy()
+	for phaseName, kinds := range file.Phases {
+		phase := -1
+		for i, n := range phaseNames {
+			if n == phaseName {
+				phase = i
+			}
+		}
+		if phase == -1 {
+			return "", nil, fmt.Errorf("%s: unknown phase %q, use early, middle or late", path, phaseName)
+		}
+		for kindName, values := range kinds {
+			kind := card.KindByName(kindName)
+			if kind == -1 {
+				return "", nil, fmt.Errorf("%s: unknown card kind %q", path, kindName)
+			}
+			if len(values) != 4 {
+				return "", nil, fmt.Errorf("%s: %s %s needs 4 values, one per level", path, phaseName, kindName)
+			}
+			for i, value := range values {
+				if value < 0 || value > 63 {
+					return "", nil, fmt.Errorf("%s: %s %s values must be from 0 to 63", path, phaseName, kindName)
+				}
+				strategy[phase][kind][i+1] = value
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,8 @@
+//go:build darwin || freebsd || netbsd || openbsd
+
+package player
+
+import "syscall"
+
+// the ioctl requests to get and set the terminal's settings
+const getTermios, setTermios = syscall.TIOCGETA, syscall.TIOCSETA
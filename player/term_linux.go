@@ -0,0 +1,6 @@
+package player
+
+import "syscall"
+
+// the ioctl requests to get and set the terminal's settings
+const getTermios, setTermios = syscall.TCGETS, syscall.TCSETS
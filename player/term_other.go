@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package player
+
+import (
+	"errors"
+	"os"
+)
+
+// rawMode isn't there on this system, so lines are read as the terminal gives them
+func rawMode(f *os.File) (restore func(), err error) {
+	return nil, errors.New("no raw mode on this system")
+}
@@ -0,0 +1,36 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package player
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func ioctl(f *os.File, request uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+/*
+rawMode has the terminal pass keys straight through as they're typed, without echoing them, so the line
+editor can see tab.  Control-C comes through as a key too, so the editor can put the terminal back before
+it stops the program.  It gives back a function that puts the terminal
+back how it was, or an error if the file isn't a terminal.
+*/
+func rawMode(f *os.File) (restore func(), err error) {
+	var old syscall.Termios
+	if err = ioctl(f, getTermios, &old); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG
+	raw.Cc[syscall.VMIN], raw.Cc[syscall.VTIME] = 1, 0
+	if err = ioctl(f, setTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() { ioctl(f, setTermios, &old) }, nil
+}
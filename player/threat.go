@@ -0,0 +1,101 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+// how likely the opponent is to attack next turn, with a soldier already on the table or only able to recruit one
+const soldierThreat = 0.8
+const militaryThreat = 0.3
+
+// where the Town Watch sits in card.Deck, the soldiers follow it by level
+var soldierIndex = card.DeckIndex(&card.Card{Name: "Town Watch"})
+
+/*
+Threat estimates how hard the opponent could hit us: reach is the most powerful attack they could make
+soon, chance how likely they are to make one.  A soldier on the table is a real threat.  A Military building
+alone means they could recruit a soldier up to its level, but they'd need to hold one and pay for it.  Each
+of those comes with its own reach and chance, and the one with the most at stake is the threat.
+*/
+func (player Player) Threat() (reach int, chance float64) {
+	opponent := player.Opponent
+	if opponent == nil {
+		return 0, 0
+	}
+	if soldiers := opponent.Top(card.Soldiers); soldiers != nil {
+		reach = soldiers.Cost + opponent.AttackBonus
+		chance = soldierThreat
+	}
+	if military := opponent.Top(card.Military); military != nil {
+		recruitReach := military.Cost + opponent.AttackBonus
+		recruitChance := player.recruitChance(military.Cost)
+		if float64(recruitReach)*recruitChance > float64(reach)*chance {
+			reach, chance = recruitReach, recruitChance
+		}
+	}
+	return
+}
+
+// recruitChance is how likely the opponent is to raise a soldier up to their military level.  If we're
+// counting cards it depends on how many of those soldiers we think they hold.
+func (player Player) recruitChance(level int) float64 {
+	if player.Tracker == nil {
+		return militaryThreat
+	}
+	holds := 0.0
+	for cost := 1; cost <= level; cost++ {
+		holds += player.OpponentMayHold(soldierIndex + cost - 1)
+	}
+	if holds > 1 {
+		holds = 1
+	}
+	return soldierThreat * holds
+}
+
+// shielded is true if our Defensive building is out of the opponent's reach, so nothing else can be taken
+func (player Player) shielded(reach int) bool {
+	shield := player.TopCard(card.Defensive)
+	return shield != nil && shield.Cost > reach
+}
+
+// exposed is what an attack could cost us without a Defensive building: the best card within reach,
+// by our strategy, plus something for every VP within reach
+func (player Player) exposed(reach int, phase int) (value int) {
+	best := 0
+	vp := 0
+	for kind := 0; kind <= 9; kind++ {
+		top := player.TopCard(kind)
+		if top == nil || kind == card.Defensive || top.Cost > reach {
+			continue
+		}
+		if v := player.Strategy[phase][kind][top.Cost]; v > best {
+			best = v
+		}
+		vp += top.VictoryPoints
+	}
+	return best + 8*vp
+}
+
+/*
+threatAdjust changes the value of a card we might build by the chance it gets taken.  A card within the
+opponent's reach is worth less, unless a Defensive building out of their reach shields the whole tableau.
+A Defensive building is worth what it protects, which grows with the VP we have on the table.
+*/
+func (player Player) threatAdjust(thiscard *card.Card, value int, phase int) int {
+	reach, chance := player.Threat()
+	if chance == 0 {
+		return value
+	}
+	if thiscard.Kind == card.Defensive {
+		if thiscard.Cost > reach {
+			return value + int(chance*float64(player.exposed(reach, phase)))
+		}
+		// it's within reach, so it just becomes the first thing they take
+		return value - int(chance*float64(value)/2)
+	}
+	if thiscard.Cost <= reach && !player.shielded(reach) {
+		// they take one card per attack, so this is only a share of the risk
+		return value - int(chance*float64(value)/2)
+	}
+	return value
+}
@@ -0,0 +1,201 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A Tracker counts cards.  There are two copies of every card in the deck, and the discard, the trash and
+both tableaus are face up, so a player can work out which cards they haven't seen.  Those are either still
+in the stock or in the opponent's hand.  When the opponent takes a card everyone can see, off the discard or
+with a soldier, we remember they hold it until it turns up again.
+
+It works from the player's view, which only has the counts of the stock and the opponent's hand.
+*/
+type Tracker struct {
+	OpponentHolds []*card.Card // cards we saw go into the opponent's hand
+
+	// card values are asked for all the time, so the count is kept until a card could have been revealed
+	countedAt [5]int
+	unseen    []int
+	pool      int
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Reset forgets everything, for a game that's been set to another position
+func (t *Tracker) Reset() {
+	t.OpponentHolds = t.OpponentHolds[:0]
+	t.unseen = nil
+}
+
+/*
+OpponentTook records a card seen going into the opponent's hand.  A card only lets go of the count once
+it's looked at, so one that went back on the discard and was taken again may still be there, and isn't
+counted twice.
+*/
+func (t *Tracker) OpponentTook(c *card.Card) {
+	if t == nil || c == nil {
+		return
+	}
+	for _, held := range t.OpponentHolds {
+		if held == c {
+			return
+		}
+	}
+	t.OpponentHolds = append(t.OpponentHolds, c)
+}
+
+// seen adds up every card the player can see, by deck index, and drops remembered opponent cards that
+// have turned up face up again
+func (view *PlayerView) seen() (counts []int) {
+	counts = make([]int, len(card.Deck))
+	visible := make(map[*card.Card]bool)
+	look := func(cards []*card.Card) {
+		for _, c := range cards {
+			if c != nil {
+				counts[card.DeckIndex(c)]++
+				visible[c] = true
+			}
+		}
+	}
+	t := view.Tracker
+	look(view.Hand.Cards)
+	for _, tableau := range []*card.Tableau{view.Tableau, view.Opponent} {
+		if tableau == nil {
+			continue
+		}
+		for _, stack := range tableau.Stack {
+			if stack != nil {
+				look(stack.Cards)
+			}
+		}
+		look(tableau.Storage)
+	}
+	look(view.DiscardPile.Cards[:view.DiscardPile.PullPos+1])
+	look(view.Trash.Cards[:view.Trash.PullPos+1])
+
+	holds := t.OpponentHolds[:0]
+	for _, c := range t.OpponentHolds {
+		if !visible[c] {
+			holds = append(holds, c)
+			counts[card.DeckIndex(c)]++
+		}
+	}
+	t.OpponentHolds = holds
+	return
+}
+
+// Unseen gives, for each deck index, how many copies could still be in the stock or the opponent's hand,
+// and pool, the number of cards they're spread across.  Don't change the slice, it's shared.
+func (view *PlayerView) Unseen() (unseen []int, pool int) {
+	t := view.Tracker
+	if t == nil {
+		return nil, 0
+	}
+	// a card can only be revealed by something coming off the stock or out of a hand, or going onto a pile
+	at := [5]int{view.Stock(), view.DiscardPile.PullPos, view.Trash.PullPos, view.OpponentHand(), len(t.OpponentHolds)}
+	if t.unseen != nil && at == t.countedAt {
+		return t.unseen, t.pool
+	}
+	unseen = view.seen()
+	for i := range unseen {
+		unseen[i] = 2 - unseen[i]
+		if unseen[i] < 0 {
+			unseen[i] = 0
+		}
+	}
+	pool = at[0] + at[3] - len(t.OpponentHolds)
+	at[4] = len(t.OpponentHolds) // seen may have let go of some
+	t.countedAt, t.unseen, t.pool = at, unseen, pool
+	return
+}
+
+// OpponentHolding gives the cards we saw go into the opponent's hand that haven't turned up since
+func (view *PlayerView) OpponentHolding() []*card.Card {
+	if view.Tracker == nil {
+		return nil
+	}
+	view.Unseen() // lets go of the ones that turned up
+	return view.Tracker.OpponentHolds
+}
+
+// DrawChance is the chance of drawing at least one copy of the deck card in the next draws off the stock
+func (player Player) DrawChance(index int, draws int) float64 {
+	unseen, pool := player.Unseen()
+	if pool <= 0 || unseen == nil {
+		return 0
+	}
+	// chance that every draw misses
+	miss := 1.0
+	for d := 0; d < draws && d < pool; d++ {
+		miss *= float64(pool-d-unseen[index]) / float64(pool-d)
+		if miss <= 0 {
+			return 1
+		}
+	}
+	return 1 - miss
+}
+
+// OpponentMayHold is how many copies of the deck card we expect the opponent has in their hand
+func (player Player) OpponentMayHold(index int) float64 {
+	unseen, pool := player.Unseen()
+	if unseen == nil {
+		return 0
+	}
+	known := 0.0
+	for _, c := range player.Tracker.OpponentHolds {
+		if card.DeckIndex(c) == index {
+			known++
+		}
+	}
+	if pool <= 0 {
+		return known
+	}
+	hidden := player.OpponentHand() - len(player.Tracker.OpponentHolds)
+	return known + float64(hidden)*float64(unseen[index])/float64(pool)
+}
+
+// ExpectedDrawValue is the average value to us of the top card of the stock, or ok is false if we
+// aren't counting cards or the stock is empty
+func (player Player) ExpectedDrawValue(phase int) (value int, ok bool) {
+	unseen, pool := player.Unseen()
+	if pool <= 0 || unseen == nil || player.Stock() == 0 {
+		return 0, false
+	}
+	total := 0
+	for i, count := range unseen {
+		if count > 0 {
+			total += count * player.CardValue(&card.Deck[i], phase)
+		}
+	}
+	return total / pool, true
+}
+
+/*
+upgradePossible says whether the cards needed to upgrade up to this one could still turn up.  It's false
+once both copies of a level in between are in the trash, the only place a card never comes back from.  One
+in the discard can still be drawn with a Market, and one in the opponent's hand or tableau can still be
+given up or taken with a soldier.
+*/
+func (player Player) upgradePossible(thiscard *card.Card) bool {
+	if player.Tracker == nil {
+		return true
+	}
+	trashed := make([]int, len(card.Deck))
+	for _, c := range player.Trash.Cards[:player.Trash.PullPos+1] {
+		if c != nil {
+			trashed[card.DeckIndex(c)]++
+		}
+	}
+	top := player.TopCard(thiscard.Kind)
+	for cost := top.Cost + 1; cost < thiscard.Cost; cost++ {
+		index := card.DeckIndex(thiscard) - thiscard.Cost + cost // levels of a kind sit together in the deck
+		if trashed[index] >= 2 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package player
+
+/*
+A UI is everything a human player is shown and everything they answer with, so the same questions can be
+put at a terminal, from a script of answers, or from another front end altogether.  The questions and what
+counts as an answer are worked out here in the player; a UI only puts them up and reads what comes back.
+
+Answers come back as the lines typed: a number from the menu, or a command like "build mine".  complete
+gives the words that could come next on a line, for a UI that can finish them.  An error, like running out
+of input, ends the game.
+*/
+type UI interface {
+	Render(view *PlayerView, news string)                                                // shows the table as the seat sees it, and what's happened lately
+	Choose(menu Menu, complete func(line string) []string) (string, error)               // puts up a menu of choices and reads the answer
+	Confirm(title, question string, complete func(line string) []string) (string, error) // asks a yes or no question and reads the answer
+	Prompt(question string, complete func(line string) []string) (string, error)         // asks for more, or again after an answer that didn't make sense
+	Notify(message string)                                                               // tells them something, like a hint or why an answer didn't work
+	Wait(message string)                                                                 // shows the table while someone else decides, with why
+}
+
+// A Menu is the choices for a decision, numbered
+type Menu struct {
+	Title    string // what's being decided, like "build"
+	Items    []MenuItem
+	Count    int    // how many to choose
+	Question string // what to ask under the menu
+}
+
+// A MenuItem is one choice on a menu, or a card that can't be chosen and why
+type MenuItem struct {
+	Number int
+	Label  string
+	Reason string // why it can't be chosen, empty if it can
+}
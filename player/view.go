@@ -0,0 +1,35 @@
+package player
+
+import (
+	"github.com/chrislunt/warwick/card"
+)
+
+/*
+A PlayerView is everything one seat may know about the game, and all a Player decides with.  It has the
+seat's own hand, both tableaus and the discard and trash, which are all face up.  Storage sits face up on
+the table, so the opponent's storage is in view too.  The stock and the opponent's hand are hidden, so all
+there is of them is how many cards they hold, and the cards the Tracker saw go into the opponent's hand.
+
+The piles are the engine's own, so the view keeps up as the game goes on.  Nothing in it leads to a card
+the seat can't see; the game package's TestViews makes sure of that.
+*/
+type PlayerView struct {
+	Seat         int
+	Hand         *card.Hand
+	Tableau      *card.Tableau
+	Opponent     *card.Tableau // the other player's tableau
+	DiscardPile  *card.Hand
+	Trash        *card.Hand
+	Stock        func() int // how many cards are left in the stock
+	OpponentHand func() int // how many cards the opponent holds
+	Tracker      *Tracker   // keeps count of the cards this player hasn't seen
+	Clock        *Clock     // how close the game is to ending
+}
+
+// DiscardTop is the card on top of the discard, or nil if it's empty
+func (v *PlayerView) DiscardTop() *card.Card {
+	if v.DiscardPile.PullPos < 0 {
+		return nil
+	}
+	return v.DiscardPile.Cards[v.DiscardPile.PullPos]
+}
@@ -0,0 +1,264 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislunt/warwick/bot"
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+// how long Join keeps trying to get back to a server it lost
+const rejoinTimeout = time.Minute
+
+// a failure that trying again won't fix, like the server turning the seat down or the player leaving
+type final struct{ error }
+
+/*
+Join plays a seat at a game served over TCP, putting its decisions to the UI until the game is over.  join
+is the request for a seat.  If the connection drops it dials again with the seat's token, for up to a minute,
+and carries on.
+*/
+func Join(addr string, join Request, ui player.UI) error {
+	join.Type = "join"
+	var lost time.Time
+	for {
+		err := join.play(addr, ui)
+		if err == nil {
+			return nil
+		}
+		if f, ok := err.(final); ok {
+			return f.error
+		}
+		if join.Token == "" {
+			return err
+		}
+		if lost.IsZero() {
+			lost = time.Now()
+			ui.Notify(fmt.Sprintf("Lost the server (%v), trying to get back", err))
+		}
+		if time.Since(lost) > rejoinTimeout {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// play plays over one connection, until the game's over or the connection is lost.  The request is kept up
+// to date with the token, to get back in with.
+func (join *Request) play(addr string, ui player.UI) error {
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	send := func(r Request) error {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = c.Write(append(data, '\n'))
+		return err
+	}
+	if err := send(*join); err != nil {
+		return err
+	}
+
+	lines := bufio.NewScanner(c)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	var news []string
+	var asked Message // the last decision, to ask again if the answer was turned down
+	decide := func(m Message) error {
+		choice, err := choose(ui, m)
+		if err != nil {
+			return final{err}
+		}
+		return send(Request{Type: "choose", ID: m.ID, Choice: &choice})
+	}
+	for lines.Scan() {
+		var m Message
+		if err := json.Unmarshal(lines.Bytes(), &m); err != nil {
+			return final{fmt.Errorf("can't read %q from the server: %v", lines.Text(), err)}
+		}
+		news = append(news, m.Events...)
+		if m.State != nil {
+			ui.Render(view(m.State), strings.Join(news, "\n"))
+		}
+		switch m.Type {
+		case "welcome":
+			join.Token, join.Seat = m.Token, &m.Seat
+			ui.Notify(fmt.Sprintf("You're player %d.  To get the seat back from somewhere else, join with -token %s", m.Seat, m.Token))
+		case "error":
+			if join.Token == "" {
+				return final{fmt.Errorf("the server says %s", m.Text)}
+			}
+			ui.Notify(m.Text)
+			if asked.Type == "decide" {
+				if err := decide(asked); err != nil {
+					return err
+				}
+			}
+		case "update":
+			ui.Wait(m.Text)
+		case "decide":
+			asked = m
+			if err := decide(m); err != nil {
+				return err
+			}
+		case "end":
+			ui.Wait(result(m))
+			return nil
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the server closed the connection")
+}
+
+// result says how the game came out, for the seat
+func result(m Message) string {
+	score := fmt.Sprintf("%d - %d", m.VP[m.Seat], m.VP[1-m.Seat])
+	switch {
+	case m.Winner == nil || *m.Winner == -1:
+		return "A tie, " + score
+	case *m.Winner == m.Seat:
+		return "You win, " + score
+	}
+	return "You lose, " + score
+}
+
+// choose puts a decision to the UI as a menu of its options, and reads answers until one is an option
+func choose(ui player.UI, m Message) (int, error) {
+	title := m.Decision
+	if m.Card != "" {
+		title += " (" + m.Card + ")"
+	}
+	menu := player.Menu{Title: title, Count: 1, Question: "Choose one, by its number or its words:"}
+	for i, label := range m.Options {
+		menu.Items = append(menu.Items, player.MenuItem{Number: i, Label: label})
+	}
+	complete := func(line string) []string { return completeOption(line, m.Options) }
+	line, err := ui.Choose(menu, complete)
+	for {
+		if err != nil {
+			return 0, err
+		}
+		choice, err := pickOption(line, m.Options)
+		if err == nil {
+			return choice, nil
+		}
+		if strings.TrimSpace(line) != "" {
+			ui.Notify(err.Error())
+		}
+		line, err = ui.Prompt(menu.Question, complete)
+	}
+}
+
+// pickOption finds the option the answer names, by its number or the start of its label
+func pickOption(line string, options []string) (int, error) {
+	typed := strings.ToLower(strings.Join(strings.Fields(line), " "))
+	if n, err := strconv.Atoi(typed); err == nil {
+		if n < 0 || n >= len(options) {
+			return 0, fmt.Errorf("%d isn't on the menu", n)
+		}
+		return n, nil
+	}
+	var matches []int
+	for i, label := range options {
+		label = strings.ToLower(label)
+		if label == typed {
+			return i, nil
+		}
+		if strings.HasPrefix(label, typed) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("%q isn't one of the options", typed)
+	}
+	var could []string
+	for _, i := range matches {
+		could = append(could, options[i])
+	}
+	return 0, fmt.Errorf("%q could be %s", typed, strings.Join(could, " or "))
+}
+
+// completeOption gives the words that could come next on the line, from the options that start the same way
+func completeOption(line string, options []string) []string {
+	typed := strings.Fields(strings.ToLower(line))
+	if strings.HasSuffix(line, " ") || line == "" {
+		typed = append(typed, "")
+	}
+	last := len(typed) - 1
+	seen := make(map[string]bool)
+	var words []string
+	for _, label := range options {
+		fields := strings.Fields(strings.ToLower(label))
+		if len(fields) <= last || strings.Join(fields[:last], " ") != strings.Join(typed[:last], " ") {
+			continue
+		}
+		if w := fields[last]; strings.HasPrefix(w, typed[last]) && !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// view puts a seat's State back together as a PlayerView, enough to draw the table from
+func view(s *bot.State) *player.PlayerView {
+	cards := func(names []string) (list []*card.Card) {
+		for _, name := range names {
+			if i := card.IndexByName(name); i >= 0 {
+				list = append(list, &card.Deck[i])
+			}
+		}
+		return
+	}
+	pile := func(names []string) *card.Hand {
+		list := cards(names)
+		return &card.Hand{Cards: list, Count: len(list), PullPos: len(list) - 1}
+	}
+	tableau := func(side bot.Side) *card.Tableau {
+		t := &card.Tableau{Stack: make(map[int]*card.Hand), Storage: make([]*card.Card, 2)}
+		for kind, names := range side.Tableau {
+			// a stack is kept with each card at its cost, as it's built
+			stack := &card.Hand{Cards: make([]*card.Card, 5)}
+			for _, c := range cards(names) {
+				stack.Cards[c.Cost], stack.PullPos = c, c.Cost
+			}
+			t.Stack[card.KindByName(kind)] = stack
+		}
+		for spot, name := range side.Storage {
+			if list := cards([]string{name}); len(list) > 0 {
+				t.Storage[spot] = list[0]
+			}
+		}
+		t.Recount()
+		return t
+	}
+	opponent := s.Seats[1-s.Seat]
+	return &player.PlayerView{
+		Seat:         s.Seat,
+		Hand:         pile(s.Hand),
+		Tableau:      tableau(s.Seats[s.Seat]),
+		Opponent:     tableau(opponent),
+		DiscardPile:  pile(s.Discard),
+		Trash:        pile(s.Trash),
+		Stock:        func() int { return s.Stock },
+		OpponentHand: func() int { return opponent.Hand },
+		Clock:        &player.Clock{Turn: s.Turn},
+	}
+}
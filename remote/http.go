@@ -0,0 +1,318 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+	"github.com/chrislunt/warwick/scenarios"
+)
+
+const (
+	maxGames = 1000             // the most games a host keeps at once, finished or not
+	maxGoing = 4                // the most unfinished games created from one address
+	keepOver = time.Hour        // how long a finished game is kept, for a last look at it
+	answerIn = 10 * time.Minute // how long a remote seat has to answer before it forfeits, which ends abandoned games
+)
+
+/*
+A Host serves any number of games over HTTP, as JSON, for front ends that aren't a terminal.  Each game is
+played at its own Table.  Whoever creates a game gets a token for each remote seat to hand out, and every
+other request about the game has to come with one, in an "Authorization: Bearer" header or a token
+parameter.  A seat only ever sees what it's allowed to, and only acts for itself.
+
+	POST /games                  create a game: {"seats":["remote","heuristic"],"names":["chris"],"seed":0,"scenario":""}
+	                             gives {"game":"3","tokens":["9c1f0e2a7b3d5e64",""]}
+	GET  /games/3/view           the table as the seat sees it, as a Message: update, decide or end
+	GET  /games/3/actions        the decision waiting on the seat and its options, or who it's waiting on
+	POST /games/3/action         answer it, with the decision's id and the index of the option: {"id":7,"choice":1}
+	GET  /games/3/log?from=0     what's happened, from the given event on: {"events":[...],"next":12}
+	GET  /games/3/stream         the view, then each Message as the table changes, as Server-Sent Events
+	GET  /cards                  the deck and the kinds of card, to draw them with
+	GET  /players                the computer players, by name, with what they're like
+
+Anything else is the web page, a game in the browser against a computer player or another person.  The seats are remote, for a player with the token, or one of the computer players by name.  Errors come
+back with a status to match and {"error":"..."}.  A seat that leaves a decision unanswered for ten minutes
+forfeits, so a game everyone has walked away from still ends, and is let go of like any other.  Anyone can
+create a game, so one address can only have a few going at once.
+*/
+type Host struct {
+	mu    sync.Mutex
+	games map[string]*hosted
+	count int           // games created, which numbers them
+	idle  time.Duration // answerIn, kept here so tests can shorten it
+	keep  time.Duration // and keepOver
+	page  http.Handler
+}
+
+// a game being hosted
+type hosted struct {
+	table *Table
+	from  string    // the address that created it
+	ended time.Time // when the game finished, zero until it has
+}
+
+// NewHost makes a host with no games yet
+func NewHost() *Host {
+	return &Host{games: make(map[string]*hosted), idle: answerIn, keep: keepOver, page: page()}
+}
+
+// Create is what POST /games takes
+type Create struct {
+	Seats    []string `json:"seats"`    // remote, or a computer player like heuristic or warlord; remote and heuristic if not given
+	Names    []string `json:"names"`    // the names of the players at the remote seats, by seat
+	Seed     int64    `json:"seed"`     // 0 for a new deal
+	Scenario string   `json:"scenario"` // the name of a built in scenario, to start from
+}
+
+// Created is what POST /games gives back
+type Created struct {
+	Game   string    `json:"game"`
+	Tokens [2]string `json:"tokens"` // by seat, empty for a computer seat
+}
+
+// an httpError is an error with the status it goes back with
+type httpError struct {
+	status int
+	error
+}
+
+func fail(status int, format string, a ...interface{}) httpError {
+	return httpError{status, fmt.Errorf(format, a...)}
+}
+
+func (h *Host) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case path[0] != "games" && path[0] != "cards" && path[0] != "players":
+		h.page.ServeHTTP(w, r)
+		return
+	case len(path) == 3 && path[2] == "stream" && r.Method == http.MethodGet:
+		// a stream is written as it goes, rather than as one reply
+		t, id, err := h.seat(path[1], r)
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		stream(w, r, t, id)
+		return
+	}
+	v, err := h.route(r)
+	reply(w, v, err)
+}
+
+// reply writes what to reply with, or the error
+func reply(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if he, ok := err.(httpError); ok {
+			status = he.status
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	if v != nil {
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// route works out what the request is for, and gives back what to reply with
+func (h *Host) route(r *http.Request) (interface{}, error) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if path[0] != "games" && len(path) == 1 {
+		if r.Method != http.MethodGet {
+			return nil, fail(http.StatusMethodNotAllowed, "%s is GET", path[0])
+		}
+		if path[0] == "cards" {
+			return cards(), nil
+		}
+		return players(), nil
+	}
+	if path[0] != "games" || len(path) > 3 {
+		return nil, fail(http.StatusNotFound, "there's nothing at %s", r.URL.Path)
+	}
+	if len(path) == 1 {
+		if r.Method != http.MethodPost {
+			return nil, fail(http.StatusMethodNotAllowed, "games are created with POST")
+		}
+		return h.create(r)
+	}
+	t, id, err := h.seat(path[1], r)
+	if err != nil {
+		return nil, err
+	}
+	what := ""
+	if len(path) == 3 {
+		what = path[2]
+	}
+	want := map[string]string{"view": http.MethodGet, "actions": http.MethodGet, "log": http.MethodGet, "action": http.MethodPost, "stream": http.MethodGet}
+	method, ok := want[what]
+	if !ok {
+		return nil, fail(http.StatusNotFound, "a game has view, actions, action, log and stream")
+	}
+	if r.Method != method {
+		return nil, fail(http.StatusMethodNotAllowed, "%s is %s", what, method)
+	}
+	switch what {
+	case "view":
+		return t.View(id), nil
+	case "actions":
+		// the decision without the table, or who it's waiting on
+		m := t.View(id)
+		m.State = nil
+		return m, nil
+	case "action":
+		var answer Request
+		if err := json.NewDecoder(r.Body).Decode(&answer); err != nil || answer.Choice == nil {
+			return nil, fail(http.StatusBadRequest, "an action is {\"id\":7,\"choice\":1}")
+		}
+		if err := t.Act(id, answer.ID, *answer.Choice); err != nil {
+			return nil, httpError{http.StatusConflict, err}
+		}
+		return map[string]bool{"ok": true}, nil
+	}
+	from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+	events := t.Events(from)
+	if events == nil {
+		events = []string{}
+	}
+	if from < 0 {
+		from = 0
+	}
+	return map[string]interface{}{"events": events, "next": from + len(events)}, nil
+}
+
+// seat finds the game and the seat the request's token is for
+func (h *Host) seat(game string, r *http.Request) (t *Table, id int, err error) {
+	h.mu.Lock()
+	g := h.games[game]
+	h.mu.Unlock()
+	if g == nil {
+		return nil, 0, fail(http.StatusNotFound, "there's no game %s", game)
+	}
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if token == "" {
+		return nil, 0, fail(http.StatusUnauthorized, "a seat's token is needed")
+	}
+	id, ok := g.table.Seat(token)
+	if !ok {
+		return nil, 0, fail(http.StatusForbidden, "that token isn't for a seat at game %s", game)
+	}
+	return g.table, id, nil
+}
+
+// create sets up a game from the request, and starts it
+func (h *Host) create(r *http.Request) (interface{}, error) {
+	c := Create{Seats: []string{"remote", "heuristic"}}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			return nil, fail(http.StatusBadRequest, "can't read the game to create: %v", err)
+		}
+	}
+	if len(c.Seats) != 2 {
+		return nil, fail(http.StatusBadRequest, "a game has 2 seats, not %d", len(c.Seats))
+	}
+	options := game.Options{Seed: c.Seed}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UTC().UnixNano()
+	}
+	if c.Scenario != "" {
+		var err error
+		if options.Scenario, err = scenarios.Get(c.Scenario); err != nil {
+			return nil, err
+		}
+	}
+	var remotes [2]bool
+	for id, spec := range c.Seats {
+		if spec == "remote" {
+			remotes[id] = true
+			options.Seats = append(options.Seats, game.Seat{})
+			continue
+		}
+		// strategy files and bot programs are for the command line, not for anyone who can reach the host
+		if _, ok := player.Personalities[spec]; !ok {
+			return nil, fail(http.StatusBadRequest, "seat %d is %q, which should be remote, heuristic, or a computer player like warlord", id, spec)
+		}
+		seat, err := game.ParseSeat(spec)
+		if err != nil {
+			return nil, err
+		}
+		options.Seats = append(options.Seats, seat)
+	}
+	if !remotes[0] && !remotes[1] {
+		return nil, fail(http.StatusBadRequest, "at least one seat should be remote")
+	}
+
+	from, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		from = r.RemoteAddr
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweep()
+	if len(h.games) >= maxGames {
+		return nil, fail(http.StatusServiceUnavailable, "there are %d games going already", len(h.games))
+	}
+	going := 0
+	for _, g := range h.games {
+		if g.from == from && g.ended.IsZero() {
+			going++
+		}
+	}
+	if going >= maxGoing {
+		return nil, fail(http.StatusTooManyRequests, "%s has %d games going already, finish one first", from, going)
+	}
+	t := NewTable(options, remotes)
+	t.Idle = h.idle
+	var created Created
+	for id := range remotes {
+		if !remotes[id] {
+			continue
+		}
+		name := ""
+		if id < len(c.Names) {
+			name = c.Names[id]
+		}
+		if _, created.Tokens[id], _ = t.Join(name, id, ""); created.Tokens[id] == "" {
+			return nil, fail(http.StatusInternalServerError, "couldn't take seat %d", id)
+		}
+	}
+	h.count++
+	created.Game = strconv.Itoa(h.count)
+	g := &hosted{table: t, from: from}
+	h.games[created.Game] = g
+	go func() {
+		t.Play()
+		h.mu.Lock()
+		g.ended = time.Now()
+		h.mu.Unlock()
+	}()
+	log(1, fmt.Sprintf("game %s created: %s", created.Game, strings.Join(c.Seats, " vs ")))
+	return created, nil
+}
+
+// sweep lets go of the games that finished a while ago.  A game left waiting on a seat that never answers
+// finishes too, when the seat forfeits after answerIn.
+func (h *Host) sweep() {
+	for id, g := range h.games {
+		if !g.ended.IsZero() && time.Since(g.ended) > h.keep {
+			delete(h.games, id)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// a caller sends requests to a host from one address, the way a front end would
+type caller struct {
+	t    *testing.T
+	h    *Host
+	from string
+}
+
+// do sends a request with the token, if there is one, and reads the reply into v.  It gives the status.
+func (c caller) do(method, target, token, body string, v interface{}) int {
+	c.t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.RemoteAddr = c.from
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	c.h.ServeHTTP(w, r)
+	if v != nil && w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			c.t.Fatalf("%s %s: can't read %q: %v", method, target, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+/*
+create creates a game, which has to work, and waits for it to stop at a remote seat.  A test leaves its games
+stopped like that when it's done, so none of them are still playing when the next test starts.
+*/
+func (c caller) create(body string) Created {
+	c.t.Helper()
+	var created Created
+	if status := c.do(http.MethodPost, "/games", "", body, &created); status != http.StatusOK {
+		c.t.Fatalf("creating %s: status %d", body, status)
+	}
+	c.await(created.Game, created.Tokens)
+	return created
+}
+
+// view is what the seat with the token is shown
+func (c caller) view(game, token string) (m Message) {
+	c.t.Helper()
+	if status := c.do(http.MethodGet, "/games/"+game+"/view", token, "", &m); status != http.StatusOK {
+		c.t.Fatalf("game %s view: status %d", game, status)
+	}
+	return
+}
+
+// await waits until the game is waiting on one of the seats with the tokens, or is over, and gives the seat
+// and what it's shown
+func (c caller) await(game string, tokens [2]string) (id int, m Message) {
+	c.t.Helper()
+	for wait := time.Now().Add(10 * time.Second); time.Now().Before(wait); time.Sleep(time.Millisecond) {
+		for id, token := range tokens {
+			if token == "" {
+				continue
+			}
+			if m = c.view(game, token); m.Type != "update" {
+				return id, m
+			}
+		}
+	}
+	c.t.Fatalf("game %s is still waiting on someone", game)
+	return
+}
+
+func (c caller) answer(game, token string, id, choice int) int {
+	c.t.Helper()
+	return c.do(http.MethodPost, "/games/"+game+"/action", token, fmt.Sprintf(`{"id":%d,"choice":%d}`, id, choice), nil)
+}
+
+/*
+TestHostTokens plays part of a game between two remote seats over HTTP.  A request needs a token for a seat
+at that game, a seat is only shown its own hand, and it can only answer its own decision, the one waiting.
+*/
+func TestHostTokens(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	c := caller{t, NewHost(), "192.0.2.1:1234"}
+	created := c.create(`{"seats":["remote","remote"],"names":["ann","bob"],"seed":1}`)
+	tokens := created.Tokens
+	if tokens[0] == "" || tokens[1] == "" || tokens[0] == tokens[1] {
+		t.Fatalf("the seats were given the tokens %q", tokens)
+	}
+	elsewhere := c.create(`{"seats":["remote","heuristic"],"seed":2}`)
+	view := "/games/" + created.Game + "/view"
+	for _, token := range []string{"", "nonsense", elsewhere.Tokens[0]} {
+		want := http.StatusForbidden
+		if token == "" {
+			want = http.StatusUnauthorized
+		}
+		if status := c.do(http.MethodGet, view, token, "", nil); status != want {
+			t.Errorf("the view with the token %q: status %d, not %d", token, status, want)
+		}
+	}
+	if status := c.do(http.MethodGet, view+"?token="+tokens[1], "", "", nil); status != http.StatusOK {
+		t.Errorf("the view with the token as a parameter: status %d", status)
+	}
+
+	for decisions := 0; decisions < 6; decisions++ {
+		id, m := c.await(created.Game, tokens)
+		if m.Type != "decide" {
+			t.Fatalf("player %d was shown %s after %d decisions", id, m.Type, decisions)
+		}
+		other := 1 - id
+		seen := c.view(created.Game, tokens[other])
+		if seen.Seat != other || seen.Type != "update" || len(seen.Options) != 0 || seen.State == nil || seen.State.Seat != other {
+			t.Fatalf("while player %d decides, player %d is shown %+v", id, other, seen.Message)
+		}
+		c.h.mu.Lock()
+		table := c.h.games[created.Game].table
+		c.h.mu.Unlock()
+		table.mu.Lock()
+		hands := [2][]string{}
+		for seat := range hands {
+			hands[seat] = []string{}
+			for _, held := range table.g.Players[seat].Hand.Cards {
+				if held != nil {
+					hands[seat] = append(hands[seat], held.Name)
+				}
+			}
+		}
+		table.mu.Unlock()
+		if !reflect.DeepEqual(seen.State.Hand, hands[other]) || !reflect.DeepEqual(m.State.Hand, hands[id]) {
+			t.Fatalf("the seats are shown the hands %q and %q, not %q", m.State.Hand, seen.State.Hand, hands)
+		}
+
+		g := created.Game
+		if status := c.answer(g, tokens[other], m.ID, 0); status != http.StatusConflict {
+			t.Errorf("player %d answering player %d's decision: status %d", other, id, status)
+		}
+		if status := c.answer(g, tokens[id], m.ID+1, 0); status != http.StatusConflict {
+			t.Errorf("answering a decision that isn't waiting: status %d", status)
+		}
+		if status := c.answer(g, tokens[id], m.ID, len(m.Options)); status != http.StatusConflict {
+			t.Errorf("answering with an option that isn't there: status %d", status)
+		}
+		if status := c.do(http.MethodPost, "/games/"+g+"/action", tokens[id], `{"id":1}`, nil); status != http.StatusBadRequest {
+			t.Errorf("answering without a choice: status %d", status)
+		}
+		if status := c.answer(g, tokens[id], m.ID, 0); status != http.StatusOK {
+			t.Fatalf("player %d answering decision %d: status %d", id, m.ID, status)
+		}
+		if status := c.answer(g, tokens[id], m.ID, 0); status != http.StatusConflict {
+			t.Errorf("answering decision %d again: status %d", m.ID, status)
+		}
+	}
+	c.await(created.Game, tokens)
+}
+
+// TestHostLog reads what's happened in a game, all of it and from partway
+func TestHostLog(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	c := caller{t, NewHost(), "192.0.2.1:1234"}
+	created := c.create(`{"seats":["remote","heuristic"],"seed":1}`)
+	token := created.Tokens[0]
+	for decisions := 0; decisions < 10; decisions++ {
+		_, m := c.await(created.Game, created.Tokens)
+		if m.Type != "decide" {
+			break
+		}
+		if status := c.answer(created.Game, token, m.ID, 0); status != http.StatusOK {
+			t.Fatalf("answering decision %d: status %d", m.ID, status)
+		}
+	}
+	c.await(created.Game, created.Tokens)
+
+	type events struct {
+		Events []string `json:"events"`
+		Next   int      `json:"next"`
+	}
+	read := func(from string) (e events) {
+		t.Helper()
+		if status := c.do(http.MethodGet, "/games/"+created.Game+"/log"+from, token, "", &e); status != http.StatusOK {
+			t.Fatalf("the log%s: status %d", from, status)
+		}
+		return
+	}
+	all := read("")
+	if len(all.Events) < 3 || all.Next != len(all.Events) {
+		t.Fatalf("the log has %d events, and goes on from %d", len(all.Events), all.Next)
+	}
+	if e := read("?from=-5"); !reflect.DeepEqual(e, all) {
+		t.Errorf("the log from -5 is %+v, not all of it", e)
+	}
+	if e := read("?from=2"); !reflect.DeepEqual(e.Events, all.Events[2:]) || e.Next != all.Next {
+		t.Errorf("the log from 2 is %+v", e)
+	}
+	if e := read(fmt.Sprintf("?from=%d", all.Next)); len(e.Events) != 0 || e.Events == nil || e.Next != all.Next {
+		t.Errorf("the log from the end is %+v", e)
+	}
+}
+
+// TestHostSweep leaves a game unanswered, which has to end and be let go of, while a game still going is kept
+func TestHostSweep(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	c := caller{t, NewHost(), "192.0.2.1:1234"}
+	c.h.idle, c.h.keep = 20*time.Millisecond, time.Millisecond
+	left := c.create(`{"seats":["remote","heuristic"],"seed":1}`)
+	if _, m := c.await(left.Game, [2]string{left.Tokens[0], ""}); m.Type != "decide" {
+		t.Fatalf("player 0 was shown %s", m.Type)
+	}
+	for wait := time.Now().Add(10 * time.Second); ; time.Sleep(time.Millisecond) {
+		c.h.mu.Lock()
+		ended := c.h.games[left.Game].ended
+		c.h.mu.Unlock()
+		if !ended.IsZero() {
+			break
+		}
+		if time.Now().After(wait) {
+			t.Fatal("the game is still waiting on a seat that never answers")
+		}
+	}
+	if m := c.view(left.Game, left.Tokens[0]); m.Type != "end" || m.Winner == nil || *m.Winner != 1 {
+		t.Errorf("player 0 was shown %s at the end", m.Type)
+	}
+	time.Sleep(5 * time.Millisecond) // longer than the host keeps a finished game
+
+	c.h.idle = time.Hour
+	going := c.create(`{"seats":["remote","heuristic"],"seed":2}`)
+	if status := c.do(http.MethodGet, "/games/"+left.Game+"/view", left.Tokens[0], "", nil); status != http.StatusNotFound {
+		t.Errorf("the finished game is still there: status %d", status)
+	}
+	if status := c.do(http.MethodGet, "/games/"+going.Game+"/view", going.Tokens[0], "", nil); status != http.StatusOK {
+		t.Errorf("the game going was let go of: status %d", status)
+	}
+}
+
+// TestHostLimit creates games from one address until it's told to finish one first, while another address
+// can still create them
+func TestHostLimit(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	c := caller{t, NewHost(), "192.0.2.1:1234"}
+	for i := 0; i < maxGoing; i++ {
+		c.create(`{}`)
+	}
+	if status := c.do(http.MethodPost, "/games", "", `{}`, nil); status != http.StatusTooManyRequests {
+		t.Errorf("game %d from one address: status %d", maxGoing+1, status)
+	}
+	c.from = "198.51.100.7:5678"
+	c.create(`{}`)
+}
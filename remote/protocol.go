@@ -0,0 +1,59 @@
+/*
+Package remote hosts a game for players on other machines.  The game is played out on the server, which is
+the only one that sees the whole table: each player is sent what their seat can see, and the answers that
+come back are checked against the legal options before the game goes on.
+
+Over TCP, the server and each client talk one JSON object to a line, much as the engine talks to a bot (see
+package bot).  A client asks for a seat, the first free one or one in particular, and is told which it got
+and a token to take it back with if the connection drops:
+
+	{"type":"join","name":"chris"}
+	{"type":"join","name":"chris","seat":1}
+	{"type":"welcome","seat":1,"token":"9c1f0e2a7b3d5e64"}
+
+	{"type":"join","token":"9c1f0e2a7b3d5e64"}
+
+The game starts as soon as it's served, and a seat's decisions wait for whoever takes it.  While someone
+else is deciding, or a seat is still empty, the client is sent the table as its seat sees it, with what's
+happened since the last message and who it's waiting on:
+
+	{"type":"update","seat":1,"state":{...},"events":["turn 2: player 0 built Mine(Supply 2 : metal)"],"text":"waiting for player 0 (dana)"}
+
+A decision is the bot protocol's decide message, with its id for the answer to give.  The answer is the
+index of the option taken:
+
+	{"type":"decide","id":7,"decision":"build","state":{...},"options":["pass","build Mine"],"events":[...]}
+	{"type":"choose","id":7,"choice":1}
+
+An answer that isn't one of the options, or isn't for the decision waiting on the seat, gets an error, and
+the decision waits for another:
+
+	{"type":"error","text":"there's no option 5, only 2"}
+
+When the game is over, everyone still connected is told how it came out, with -1 for a tie:
+
+	{"type":"end","seat":1,"state":{...},"vp":[12,9],"winner":0}
+*/
+package remote
+
+import (
+	"github.com/chrislunt/warwick/bot"
+)
+
+// Message is anything the server sends.  Only the fields that go with the type are set.
+type Message struct {
+	bot.Message
+	Token  string   `json:"token,omitempty"`
+	Text   string   `json:"text,omitempty"` // who's being waited on, or what was wrong with a request
+	Events []string `json:"events,omitempty"`
+}
+
+// Request is anything a client sends
+type Request struct {
+	Type   string `json:"type"` // join or choose
+	Name   string `json:"name,omitempty"`
+	Seat   *int   `json:"seat,omitempty"`  // the seat wanted, nil for the first one free
+	Token  string `json:"token,omitempty"` // to take back a seat
+	ID     int    `json:"id,omitempty"`
+	Choice *int   `json:"choice,omitempty"`
+}
@@ -0,0 +1,186 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// how long a new connection has to ask for a seat
+const joinTimeout = 30 * time.Second
+
+// a server carries a table's messages over TCP
+type server struct {
+	table   *Table
+	mu      sync.Mutex
+	conns   [2]net.Conn       // whoever has each seat now
+	joining map[net.Conn]bool // connected but not seated yet, closed when the game is over
+	over    bool
+}
+
+/*
+Serve hosts the table for clients connecting to the listener, like Join, until the game is over and everyone
+still connected has been told how it came out.  A connection that hasn't asked for a seat by then is
+closed.  The table should be playing already.  A player whose
+connection drops can come back with their token and carry on where they were.
+*/
+func Serve(l net.Listener, t *Table) error {
+	s := &server{table: t, joining: make(map[net.Conn]bool)}
+	var wg sync.WaitGroup
+	stopped := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				stopped <- err
+				return
+			}
+			s.mu.Lock()
+			if s.over {
+				s.mu.Unlock()
+				conn.Close()
+				continue
+			}
+			s.joining[conn] = true
+			s.mu.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handle(conn)
+			}()
+		}
+	}()
+	select {
+	case <-t.Done():
+		l.Close()
+		// no one is left to join, so don't wait out their time to ask
+		s.mu.Lock()
+		s.over = true
+		for conn := range s.joining {
+			conn.Close()
+		}
+		s.mu.Unlock()
+	case err := <-stopped:
+		return err
+	}
+	wg.Wait()
+	return nil
+}
+
+// a conn is one client's connection, which writes a message at a time
+type conn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+func (c *conn) send(m Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.Write(append(data, '\n'))
+	return err
+}
+
+// refuse tells the client what was wrong with what it sent
+func (c *conn) refuse(err error) {
+	m := Message{Text: err.Error()}
+	m.Type = "error"
+	c.send(m)
+}
+
+// seated is done with a connection's join, whether it got a seat or not
+func (s *server) seated(nc net.Conn) {
+	s.mu.Lock()
+	delete(s.joining, nc)
+	s.mu.Unlock()
+}
+
+// handle seats a client, then sends it the table as it changes and passes on its answers
+func (s *server) handle(nc net.Conn) {
+	c := &conn{Conn: nc}
+	defer c.Close()
+	defer s.seated(nc)
+	lines := bufio.NewScanner(c)
+	c.SetReadDeadline(time.Now().Add(joinTimeout))
+	if !lines.Scan() {
+		return
+	}
+	var join Request
+	if err := json.Unmarshal(lines.Bytes(), &join); err != nil || join.Type != "join" {
+		c.refuse(fmt.Errorf("ask for a seat first, with join"))
+		return
+	}
+	c.SetReadDeadline(time.Time{})
+	want := -1
+	if join.Seat != nil {
+		want = *join.Seat
+	}
+	id, token, err := s.table.Join(join.Name, want, join.Token)
+	if err != nil {
+		c.refuse(err)
+		return
+	}
+	s.seated(nc)
+	// a seat taken back leaves the old connection behind
+	s.mu.Lock()
+	if old := s.conns[id]; old != nil {
+		old.Close()
+	}
+	s.conns[id] = c
+	s.mu.Unlock()
+	log(1, fmt.Sprintf("%s joined from %s", s.table.Name(id), c.RemoteAddr()))
+	welcome := Message{Token: token}
+	welcome.Type, welcome.Seat = "welcome", id
+	if c.send(welcome) != nil {
+		return
+	}
+
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for lines.Scan() {
+			var r Request
+			if err := json.Unmarshal(lines.Bytes(), &r); err != nil {
+				c.refuse(fmt.Errorf("can't read %q: %v", lines.Text(), err))
+				continue
+			}
+			if r.Type != "choose" || r.Choice == nil {
+				c.refuse(fmt.Errorf("the only thing to send now is choose, with a choice"))
+				continue
+			}
+			if err := s.table.Act(id, r.ID, *r.Choice); err != nil {
+				c.refuse(err)
+			}
+		}
+	}()
+
+	seen := 0
+	var last Message
+	for {
+		m, changed := s.table.Message(id, seen)
+		seen += len(m.Events)
+		// the same decision again, or nothing new while waiting, isn't worth sending
+		same := m.Type == last.Type && m.ID == last.ID && m.Text == last.Text && len(m.Events) == 0
+		if !same {
+			if c.send(m) != nil {
+				break
+			}
+			last = m
+		}
+		if m.Type == "end" {
+			return
+		}
+		select {
+		case <-changed:
+		case <-gone:
+			log(1, fmt.Sprintf("%s dropped", s.table.Name(id)))
+			return
+		}
+	}
+}
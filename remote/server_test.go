@@ -0,0 +1,207 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// a client talks to the server the way a remote player's program would, a JSON object to a line
+type client struct {
+	t     *testing.T
+	conn  net.Conn
+	lines *bufio.Scanner
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client{t: t, conn: conn, lines: bufio.NewScanner(conn)}
+}
+
+func (c *client) send(r Request) {
+	c.t.Helper()
+	data, _ := json.Marshal(r)
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// read gives the next message
+func (c *client) read() Message {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if !c.lines.Scan() {
+		c.t.Fatalf("the connection ended: %v", c.lines.Err())
+	}
+	var m Message
+	if err := json.Unmarshal(c.lines.Bytes(), &m); err != nil {
+		c.t.Fatalf("can't read %q: %v", c.lines.Text(), err)
+	}
+	return m
+}
+
+// a message that came in on one of the clients
+type received struct {
+	id int
+	c  *client
+	m  Message
+}
+
+// listen passes on everything the client is sent, until the connection ends
+func (c *client) listen(id int, inbox chan<- received) {
+	go func() {
+		for c.lines.Scan() {
+			var m Message
+			if json.Unmarshal(c.lines.Bytes(), &m) == nil {
+				inbox <- received{id, c, m}
+			}
+		}
+	}()
+}
+
+func (c *client) join(r Request) Message {
+	c.t.Helper()
+	r.Type = "join"
+	c.send(r)
+	m := c.read()
+	if m.Type != "welcome" {
+		c.t.Fatalf("asked for a seat and got %s: %s", m.Type, m.Text)
+	}
+	return m
+}
+
+/*
+TestServe plays a game between two clients over loopback.  One of them gives an answer that isn't an option
+first, and later drops its connection and comes back with its token.  A third connects and never asks for a
+seat, which mustn't keep Serve from returning once the game is over.
+*/
+func TestServe(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	table := NewTable(game.Options{Seed: 1, TurnLimit: 6}, [2]bool{true, true})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- Serve(l, table) }()
+	go table.Play()
+	addr := l.Addr().String()
+
+	idle := dial(t, addr)
+	defer idle.conn.Close()
+	inbox := make(chan received, 100)
+	var clients [2]*client
+	var tokens [2]string
+	for id := range clients {
+		clients[id] = dial(t, addr)
+		welcome := clients[id].join(Request{Name: []string{"ann", "bob"}[id]})
+		if welcome.Seat != id || welcome.Token == "" {
+			t.Fatalf("client %d got seat %d with token %q", id, welcome.Seat, welcome.Token)
+		}
+		tokens[id] = welcome.Token
+		clients[id].conn.SetReadDeadline(time.Time{})
+		clients[id].listen(id, inbox)
+	}
+	late := dial(t, addr)
+	late.send(Request{Type: "join", Name: "cat"})
+	if m := late.read(); m.Type != "error" || !strings.Contains(m.Text, "no free seats") {
+		t.Errorf("a third player asking for a seat got %s: %s", m.Type, m.Text)
+	}
+	late.conn.Close()
+
+	choose := func(c *client, id, choice int) {
+		c.send(Request{Type: "choose", ID: id, Choice: &choice})
+	}
+	decisions, refused, rejoined, again := 0, -1, false, 0
+	var ended [2]bool
+	for !ended[0] || !ended[1] {
+		var r received
+		select {
+		case r = <-inbox:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("nothing came from the server after %d decisions", decisions)
+		}
+		// a dropped connection's last words don't count, and updates only say who's deciding
+		if r.c != clients[r.id] || r.m.Type == "update" {
+			continue
+		}
+		id, c, m := r.id, r.c, r.m
+		switch m.Type {
+		case "end":
+			ended[id] = true
+			if len(m.VP) != 2 || m.Winner == nil {
+				t.Errorf("player %d was told the game ended without the score", id)
+			}
+			continue
+		case "error":
+			if refused == -1 || !strings.Contains(m.Text, "there's no option") {
+				t.Fatalf("player %d was sent an error: %s", id, m.Text)
+			}
+			choose(c, refused, 0)
+			refused = 0
+			continue
+		case "decide":
+		default:
+			t.Fatalf("player %d was sent %s: %s", id, m.Type, m.Text)
+		}
+		if m.Seat != id || len(m.Options) == 0 || m.State == nil {
+			t.Fatalf("player %d was asked %+v", id, m.Message)
+		}
+		if again != 0 {
+			if m.ID != again {
+				t.Fatalf("back at the table, player %d was asked decision %d, not %d again", id, m.ID, again)
+			}
+			again = 0
+			choose(c, m.ID, 0)
+			continue
+		}
+		decisions++
+		switch {
+		case refused == -1:
+			// one answer that isn't an option, which gets an error and leaves the decision waiting
+			choose(c, m.ID, len(m.Options))
+			refused = m.ID
+		case decisions == 5:
+			// the connection drops before answering, and the decision is waiting when the seat is taken back
+			c.conn.Close()
+			c = dial(t, addr)
+			clients[id] = c
+			if welcome := c.join(Request{Token: tokens[id]}); welcome.Seat != id {
+				t.Fatalf("player %d's token took back seat %d", id, welcome.Seat)
+			}
+			c.conn.SetReadDeadline(time.Time{})
+			c.listen(id, inbox)
+			rejoined, again = true, m.ID
+		default:
+			choose(c, m.ID, 0)
+		}
+	}
+	if refused != 0 {
+		t.Errorf("no one was told their answer wasn't an option")
+	}
+	if !rejoined {
+		t.Errorf("the game ended after %d decisions, before a connection could drop", decisions)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve is still waiting on a connection that never asked for a seat")
+	}
+	if g := table.Game(); !g.Over || g.Ending != game.EndTurnLimit {
+		t.Errorf("the game ended by %s", game.EndingName(g.Ending))
+	}
+}
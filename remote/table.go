@@ -0,0 +1,280 @@
+package remote
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chrislunt/warwick/bot"
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// LogLevel controls how much the server says about who's come and gone
+var LogLevel = 1
+
+func log(level int, message string) {
+	if LogLevel >= level {
+		fmt.Println(message)
+	}
+}
+
+/*
+A Table is a game hosted for remote players.  The computer seats play as usual, and the game stops at each
+decision for a remote seat until an answer comes in with Act.  Whatever carries the messages, everything goes
+through the table: it knows whose decision is waiting, checks each answer against the options, and only
+shows a seat what that seat can see.
+
+The game holds the lock while it plays, and lets go while it waits on a remote seat, so anyone looking at
+the table finds it between moves.
+*/
+type Table struct {
+	Idle time.Duration // how long a remote seat has to answer before it forfeits, 0 to wait for ever
+
+	mu      sync.Mutex
+	g       *game.Game
+	seats   [2]*seat       // nil for a computer seat
+	pending *game.Decision // the decision waiting on a remote seat, nil while the game plays or once it's over
+	asked   int            // counts the decisions put to remote seats, so an answer can say which it's for
+	answers chan int
+	changed chan struct{} // closed when there's something new to see, and made again
+	done    chan struct{} // closed when the game is over
+}
+
+// a seat played from somewhere else
+type seat struct {
+	name  string
+	token string
+	taken bool
+}
+
+// NewTable sets up a game with the seats marked remote played from somewhere else, and the rest as the
+// options say.  The game doesn't start until Play.
+func NewTable(options game.Options, remote [2]bool) *Table {
+	t := &Table{answers: make(chan int, 1), changed: make(chan struct{}), done: make(chan struct{})}
+	seats := make([]game.Seat, 2)
+	copy(seats, options.Seats)
+	for id := range remote {
+		if remote[id] {
+			t.seats[id] = &seat{token: newToken()}
+			seats[id] = game.Seat{Name: "remote", Chooser: t}
+		}
+	}
+	options.Seats = seats
+	t.g = game.New(options)
+	return t
+}
+
+// newToken makes a secret for a seat, hard enough to guess that only the one it's given to has it
+func newToken() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Play plays the game out, waiting on the remote seats as it goes
+func (t *Table) Play() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for !t.g.Over {
+		t.g.PlayTurn()
+	}
+	t.bump()
+	close(t.done)
+}
+
+// Done is closed when the game is over
+func (t *Table) Done() <-chan struct{} {
+	return t.done
+}
+
+// Game is the game at the table, which mustn't be looked at until it's over
+func (t *Table) Game() *game.Game {
+	return t.g
+}
+
+// bump lets everyone watching know there's something new
+func (t *Table) bump() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+/*
+Choose puts the decision to the remote seat, and waits for the answer.  A seat that leaves it unanswered for
+longer than Idle forfeits, so a game no one comes back to still ends.  Once the game is over, the rest of the
+turn plays out with the first option.
+*/
+func (t *Table) Choose(view *player.PlayerView, d game.Decision) int {
+	if t.g.Over {
+		return 0
+	}
+	t.asked++
+	t.pending = &d
+	t.bump()
+	var idle <-chan time.Time
+	if t.Idle > 0 {
+		timer := time.NewTimer(t.Idle)
+		defer timer.Stop()
+		idle = timer.C
+	}
+	t.mu.Unlock()
+	select {
+	case choice := <-t.answers:
+		t.mu.Lock()
+		return choice
+	case <-idle:
+	}
+	t.mu.Lock()
+	if t.pending == nil {
+		// the answer came in just as time ran out
+		return <-t.answers
+	}
+	t.pending = nil
+	log(1, fmt.Sprintf("%s left the game waiting for %v", t.who(d.Seat), t.Idle))
+	d.Forfeit(fmt.Sprintf("no answer in %v", t.Idle))
+	return 0
+}
+
+// Act answers the decision waiting on the seat, asked says which decision it's for and choice is the index of
+// the option taken
+func (t *Table) Act(id, asked, choice int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending == nil || t.pending.Seat != id {
+		return fmt.Errorf("there's no decision waiting on player %d", id)
+	}
+	if asked != t.asked {
+		return fmt.Errorf("decision %d isn't the one waiting, %d is", asked, t.asked)
+	}
+	if choice < 0 || choice >= len(t.pending.Options) {
+		return fmt.Errorf("there's no option %d, only %d", choice, len(t.pending.Options))
+	}
+	t.pending = nil
+	t.answers <- choice
+	return nil
+}
+
+/*
+Join takes a remote seat for a player.  With a token it's the seat the token was given for, taken back;
+without one it's the seat asked for, or the first one free for -1, as long as no one has it yet.  It gives
+the seat and the token that takes it back.
+*/
+func (t *Table) Join(name string, want int, token string) (id int, key string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if token != "" {
+		if id, ok := t.Seat(token); ok {
+			return id, token, nil
+		}
+		return 0, "", fmt.Errorf("that token isn't for a seat at this table")
+	}
+	for id, s := range t.seats {
+		if s == nil || s.taken || (want != -1 && want != id) {
+			continue
+		}
+		s.name, s.taken = name, true
+		t.bump()
+		return id, s.token, nil
+	}
+	if want != -1 {
+		return 0, "", fmt.Errorf("player %d isn't free", want)
+	}
+	return 0, "", fmt.Errorf("there are no free seats")
+}
+
+// Seat finds the remote seat a token is for
+func (t *Table) Seat(token string) (id int, ok bool) {
+	for id, s := range t.seats {
+		if s != nil && subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1 {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Name names a seat, with the name of whoever has it
+func (t *Table) Name(id int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.who(id)
+}
+
+// who names a seat, with the name of whoever has it
+func (t *Table) who(id int) string {
+	if s := t.seats[id]; s != nil && s.name != "" {
+		return fmt.Sprintf("player %d (%s)", id, s.name)
+	}
+	return fmt.Sprintf("player %d", id)
+}
+
+// waiting says who the table is waiting on
+func (t *Table) waiting() string {
+	for id, s := range t.seats {
+		if s != nil && !s.taken {
+			return fmt.Sprintf("waiting for %s to join", t.who(id))
+		}
+	}
+	if t.pending != nil {
+		return fmt.Sprintf("waiting for %s", t.who(t.pending.Seat))
+	}
+	return ""
+}
+
+/*
+Message is what a seat should be shown now, with the events after the first seen.  It also gives a channel
+that's closed when there's something newer.
+*/
+func (t *Table) Message(id int, seen int) (m Message, changed <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m = t.message(id)
+	m.Events = t.events(seen)
+	return m, t.changed
+}
+
+// View is what a seat should be shown now, without the events
+func (t *Table) View(id int) Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.message(id)
+}
+
+// Events are what's happened, from the given one on
+func (t *Table) Events(from int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.events(from)
+}
+
+// message is the decision waiting on the seat, how the game came out, or else the table as it stands
+func (t *Table) message(id int) (m Message) {
+	switch {
+	case t.g.Over:
+		winner := t.g.Winner()
+		m.Message = bot.Message{Type: "end", VP: t.g.VictoryPoints(), Winner: &winner}
+	case t.pending != nil && t.pending.Seat == id:
+		m.Message, m.ID = bot.Ask(t.g.Players[id].PlayerView, *t.pending), t.asked
+	default:
+		m.Message, m.Text = bot.Message{Type: "update"}, t.waiting()
+	}
+	m.Seat = id
+	if m.State == nil {
+		m.State = bot.See(t.g.Players[id].PlayerView)
+	}
+	return
+}
+
+func (t *Table) events(from int) (list []string) {
+	if from < 0 {
+		from = 0
+	}
+	for i := from; i < len(t.g.Events); i++ {
+		list = append(list, t.g.Events[i].String())
+	}
+	return
+}
@@ -0,0 +1,31 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// TestIdle leaves a remote seat's decision unanswered, and the seat has to forfeit so the game ends
+func TestIdle(t *testing.T) {
+	LogLevel, game.LogLevel, player.LogLevel = 0, 0, 0
+	table := NewTable(game.Options{Seed: 1}, [2]bool{true, false})
+	table.Idle = 20 * time.Millisecond
+	go table.Play()
+	select {
+	case <-table.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the game is still waiting on a seat that never answers")
+	}
+	if g := table.Game(); g.Ending != game.EndForfeit || g.Forfeited != 0 {
+		t.Errorf("the game ended by %s, with player %d forfeiting", game.EndingName(g.Ending), g.Forfeited)
+	}
+	if m := table.View(0); m.Type != "end" || m.Winner == nil || *m.Winner != 1 {
+		t.Errorf("player 0 was shown %s at the end", m.Type)
+	}
+	if err := table.Act(0, 1, 0); err == nil {
+		t.Errorf("an answer after the seat forfeited was taken")
+	}
+}
@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/player"
+)
+
+/*
+The web page is a game in the browser, for anyone who'd rather not use a terminal.  It's a page and a
+script, built into the binary, that start a game with the API (see Host) and draw the table from the messages
+streamed to it as Server-Sent Events.  The player answers by clicking: a card in their hand to build it, the
+cards to pay with, the opponent's card to attack, the stock or discard to store or draw from.
+*/
+
+//go:embed web
+var webFiles embed.FS
+
+// page serves the files of the web page
+func page() http.Handler {
+	files, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
+
+// a deckCard is what the page needs to know to draw a card
+type deckCard struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Cost     int    `json:"cost"`
+	Material string `json:"material"`
+	VP       int    `json:"vp"`
+	Rule     string `json:"rule"`
+}
+
+// cards are every card in the deck, and the kinds in the order a tableau is drawn, for GET /cards
+func cards() interface{} {
+	var list []deckCard
+	for _, c := range card.Deck {
+		list = append(list, deckCard{c.Name, card.KindName(c.Kind), c.Cost, card.MaterialName(c.Material), c.VictoryPoints, c.Rule})
+	}
+	var kinds []string
+	for kind := 0; kind <= card.Soldiers; kind++ {
+		kinds = append(kinds, card.KindName(kind))
+	}
+	return map[string]interface{}{"kinds": kinds, "cards": list}
+}
+
+// players are the computer players a game can be created against, for GET /players
+func players() interface{} {
+	var list []map[string]string
+	for name, p := range player.Personalities {
+		list = append(list, map[string]string{"name": name, "description": p.Description})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i]["name"] < list[j]["name"] })
+	return list
+}
+
+/*
+stream sends the seat's Message as a Server-Sent Event whenever there's something new, until the game is over
+or the browser goes away.  Each event's id is how many of the game's events have been sent, so a browser that
+reconnects, sending the last one it had, only gets the ones it missed.
+*/
+func stream(w http.ResponseWriter, r *http.Request, t *Table, id int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "can't stream from here"})
+		return
+	}
+	seen, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	var last Message
+	for {
+		m, changed := t.Message(id, seen)
+		seen += len(m.Events)
+		// as over TCP, the same decision again, or nothing new while waiting, isn't worth sending
+		if m.Type != last.Type || m.ID != last.ID || m.Text != last.Text || len(m.Events) > 0 {
+			data, err := json.Marshal(m)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", seen, data)
+			flusher.Flush()
+			last = m
+		}
+		if m.Type == "end" {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
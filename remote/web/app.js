@@ -0,0 +1,310 @@
+// The browser's side of a game at warwick host.  It starts a game, or picks one up from the link, draws the
+// table from the messages the server streams, and turns clicks on the cards into the options of the
+// decision in front of the player.  The options are always there as buttons too, for anything not on the
+// table, like passing.
+"use strict";
+
+const deck = {};     // every card, by name
+let kinds = [];      // the kinds of card, in the order a tableau is drawn
+let game = null;     // {id, token} of the game being played
+let current = null;  // the last message from the server
+let picked = [];     // the cards picked to pay for a build, as {key, label}
+
+const $ = (id) => document.getElementById(id);
+
+function status(text) {
+  $("status").textContent = text || "";
+}
+
+async function api(method, path, body) {
+  const headers = {"Content-Type": "application/json"};
+  if (game) headers.Authorization = "Bearer " + game.token;
+  const response = await fetch(path, {method, headers, body: body && JSON.stringify(body)});
+  const reply = await response.json();
+  if (!response.ok) throw new Error(reply.error);
+  return reply;
+}
+
+async function start() {
+  const [cards, players] = await Promise.all([api("GET", "/cards"), api("GET", "/players")]);
+  kinds = cards.kinds;
+  for (const c of cards.cards) deck[c.name] = c;
+  for (const p of players) {
+    const option = document.createElement("option");
+    option.value = p.name;
+    option.textContent = "the computer: " + p.name + ", which " + p.description;
+    $("opponents").insertBefore(option, $("opponents").lastElementChild);
+  }
+  $("opponents").value = "heuristic";
+  const hash = new URLSearchParams(location.hash.slice(1));
+  if (hash.get("game") && hash.get("token")) {
+    join(hash.get("game"), hash.get("token"));
+  } else {
+    $("lobby").hidden = false;
+  }
+}
+
+$("create").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const form = new FormData(e.target);
+  const second = form.get("second") !== null;
+  const mine = second ? 1 : 0;
+  const seats = ["remote", "remote"];
+  const names = ["", ""];
+  seats[1 - mine] = form.get("opponent");
+  names[mine] = form.get("name");
+  try {
+    const created = await api("POST", "/games", {seats, names});
+    if (form.get("opponent") === "remote") {
+      $("invite-link").value = location.origin + location.pathname + link(created.game, created.tokens[1 - mine]);
+      $("invite").hidden = false;
+    }
+    location.hash = link(created.game, created.tokens[mine]);
+    $("lobby").hidden = true;
+    join(created.game, created.tokens[mine]);
+  } catch (err) {
+    status(err.message);
+  }
+});
+
+function link(id, token) {
+  return "#game=" + encodeURIComponent(id) + "&token=" + encodeURIComponent(token);
+}
+
+// join follows the game as the seat, until it's over.  EventSource reconnects by itself if the connection
+// drops, and the server only sends what was missed.
+function join(id, token) {
+  game = {id, token};
+  $("table").hidden = false;
+  const events = new EventSource("/games/" + encodeURIComponent(id) + "/stream?token=" + encodeURIComponent(token));
+  events.onmessage = (e) => {
+    status("");
+    const m = JSON.parse(e.data);
+    show(m);
+    if (m.type === "end") events.close();
+  };
+  events.onerror = () => {
+    if (events.readyState === EventSource.CLOSED) {
+      status("Can't follow the game: it may be over, or the link may be wrong");
+    } else {
+      status("Lost the server, trying to get back");
+    }
+  };
+}
+
+function show(m) {
+  if (!current || current.id !== m.id || m.type !== "decide") picked = [];
+  current = m;
+  for (const event of m.events || []) {
+    const item = document.createElement("li");
+    item.textContent = event;
+    $("log").prepend(item);
+  }
+  if (m.state) draw(m.state);
+  ask(m);
+}
+
+// act answers the decision with the option, once
+async function act(choice) {
+  if (!current || current.type !== "decide" || current.answered) return;
+  current.answered = true;
+  try {
+    await api("POST", "/games/" + encodeURIComponent(game.id) + "/action", {id: current.id, choice});
+  } catch (err) {
+    current.answered = false;
+    status(err.message);
+  }
+}
+
+// what each decision asks, in words
+const questions = {
+  "build": () => "Build something, or pass",
+  "discards": (m) => "Pick " + cost(m) + " card" + (cost(m) === 1 ? "" : "s") + " to pay for " + m.card,
+  "redraw": () => "Nothing to build.  Keep your hand, or throw it in and draw again?",
+  "attack": () => "Send your soldiers after one of their cards?",
+  "defend": (m) => "Their " + m.card + " is after your " + m.target + ".  Defend?",
+  "store": () => "Fill a storage spot",
+  "trash": () => "Trash a card to draw another, or pass",
+  "draw": (m) => "Draw " + m.card + " from the discard, or from the stock?",
+  "hand limit": () => "You're over the hand limit: give up a card",
+};
+
+function ask(m) {
+  const options = $("options");
+  options.replaceChildren();
+  if (m.type === "end") {
+    const score = m.vp[m.seat] + " - " + m.vp[1 - m.seat];
+    const winner = m.winner === undefined ? -1 : m.winner;
+    $("asking").textContent = winner === -1 ? "A tie, " + score : winner === m.seat ? "You win, " + score : "You lose, " + score;
+    const again = document.createElement("button");
+    again.textContent = "Play again";
+    again.onclick = () => { location.hash = ""; location.reload(); };
+    options.append(again);
+    return;
+  }
+  if (m.type !== "decide") {
+    $("asking").textContent = m.text || "";
+    return;
+  }
+  const question = questions[m.decision];
+  $("asking").textContent = question ? question(m) : m.decision;
+  if (m.decision === "discards") {
+    const paying = document.createElement("p");
+    paying.textContent = picked.length ? "Paying with " + picked.map((p) => p.label).join(", ") : "Click the cards to pay with, or pick a way below";
+    options.append(paying);
+  }
+  m.options.forEach((label, i) => {
+    const button = document.createElement("button");
+    button.textContent = label;
+    button.onclick = () => act(i);
+    options.append(button);
+  });
+}
+
+// cost is how many cards a build takes, from the first way to pay for it
+function cost(m) {
+  return m.options.length ? paying(m.options[0]).length : 0;
+}
+
+// paying gives the cards a discards option pays with
+function paying(label) {
+  return label.replace(/^discard /, "").split(", ");
+}
+
+// labelFor gives the option a click on a card would take, if there is one.  The place is where the card
+// is: hand, storage, theirs (the top of one of the opponent's stacks), mine, discard or stock.
+function labelFor(place, name) {
+  const held = place === "storage" ? "stored " + name : name;
+  const holding = place === "hand" || place === "storage";
+  switch (current.decision) {
+    case "build": return holding && "build " + held;
+    case "trash": return holding && "trash " + held;
+    case "hand limit": return place === "hand" && "give up " + name;
+    case "attack": return place === "theirs" && "take " + name;
+    case "defend": return place === "mine" && "defend with " + name;
+    case "store":
+      if (place === "stock") return "store from the stock";
+      if (place === "discard") return "store " + name + " from the discard";
+      return place === "hand" && "store " + name;
+    case "draw":
+      if (place === "stock") return "draw from the stock";
+      return place === "discard" && "draw " + name + " from the discard";
+  }
+  return "";
+}
+
+// click takes the option for the card, or for discards, adds it to the cards to pay with, or takes it back
+function click(place, name, key) {
+  if (!current || current.type !== "decide" || current.answered) return;
+  if (current.decision === "discards") {
+    const held = place === "storage" ? "stored " + name : name;
+    const already = picked.findIndex((p) => p.key === key);
+    if (already >= 0) {
+      picked.splice(already, 1);
+    } else {
+      picked.push({key, label: held});
+    }
+    if (picked.length === cost(current)) {
+      const label = "discard " + picked.map((p) => p.label).sort().join(", ");
+      picked = [];
+      const choice = current.options.indexOf(label);
+      if (choice >= 0) {
+        act(choice);
+      } else {
+        status("That's not a way to pay for it");
+      }
+    }
+    draw(current.state);
+    ask(current);
+    return;
+  }
+  const choice = current.options.indexOf(labelFor(place, name));
+  if (choice >= 0) act(choice);
+}
+
+// choosable says if clicking the card would do anything
+function choosable(place, name) {
+  if (!current || current.type !== "decide") return false;
+  if (current.decision === "discards") {
+    const held = place === "storage" ? "stored " + name : name;
+    return (place === "hand" || place === "storage") && current.options.some((o) => paying(o).includes(held));
+  }
+  return current.options.includes(labelFor(place, name));
+}
+
+// cardFor makes a card to put on the table.  Without a place it's just to look at.
+function cardFor(name, place, key) {
+  const c = deck[name] || {name, kind: "", cost: "", material: "", vp: 0, rule: ""};
+  const div = document.createElement("div");
+  div.className = "card " + c.material;
+  div.title = c.rule;
+  const vp = c.vp ? " ★" + c.vp : "";
+  div.innerHTML = '<span class="name"></span><span class="about"></span>';
+  div.firstChild.textContent = c.name;
+  div.lastChild.textContent = c.kind + " " + c.cost + vp;
+  if (place && choosable(place, name)) {
+    div.classList.add("choosable");
+    div.onclick = () => click(place, name, key);
+  }
+  if (picked.some((p) => p.key === key)) div.classList.add("picked");
+  return div;
+}
+
+function empty(text) {
+  const div = document.createElement("div");
+  div.className = "card empty";
+  div.textContent = text;
+  return div;
+}
+
+// tableau draws a side's stacks, each with the cards under the top one peeking out
+function tableau(side, place) {
+  const row = [];
+  for (const kind of kinds) {
+    const stack = side.tableau[kind];
+    if (!stack || !stack.length) continue;
+    const div = document.createElement("div");
+    div.className = "stack";
+    stack.forEach((name, i) => {
+      const top = i === stack.length - 1;
+      const c = cardFor(name, top ? place : "", place + kind + i);
+      if (!top) c.classList.add("under");
+      div.append(c);
+    });
+    row.push(div);
+  }
+  return row.length ? row : [empty("nothing built")];
+}
+
+function storage(side, place) {
+  return side.storage.map((name, i) => name ? cardFor(name, place, place + i) : empty("empty"));
+}
+
+function draw(s) {
+  const me = s.seats[s.seat];
+  const them = s.seats[1 - s.seat];
+  $("my-name").textContent = "You, player " + s.seat + ": " + me.vp + " VP";
+  $("their-name").textContent = "Opponent, player " + (1 - s.seat) + ": " + them.vp + " VP, turn " + s.turn;
+  $("my-tableau").replaceChildren(...tableau(me, "mine"));
+  $("their-tableau").replaceChildren(...tableau(them, "theirs"));
+  $("my-storage").replaceChildren(...storage(me, "storage"));
+  $("their-storage").replaceChildren(...storage(them, ""));
+  const holds = s.opponent_holds && s.opponent_holds.length ? ", including " + s.opponent_holds.join(", ") : "";
+  $("their-hand").textContent = "they hold " + them.hand + " card" + (them.hand === 1 ? "" : "s") + holds;
+  $("hand").replaceChildren(...(s.hand.length ? s.hand.map((name, i) => cardFor(name, "hand", "hand" + i)) : [empty("no cards")]));
+
+  const stock = empty(s.stock + " cards");
+  if (choosable("stock", "")) {
+    stock.classList.add("choosable");
+    stock.onclick = () => click("stock", "");
+  }
+  $("stock").replaceChildren(stock);
+  const top = s.discard[s.discard.length - 1];
+  $("discard").replaceChildren(top ? cardFor(top, "discard", "discard") : empty("empty"));
+  if (top) $("discard").append(" " + s.discard.length);
+  const trashed = s.trash[s.trash.length - 1];
+  $("trash").replaceChildren(trashed ? cardFor(trashed) : empty("empty"));
+  if (trashed) $("trash").append(" " + s.trash.length);
+}
+
+start().catch((err) => status(err.message));
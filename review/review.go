@@ -0,0 +1,176 @@
+/*
+Package review goes back over a finished game and finds the decisions that cost a player the most.  Each
+decision in the game log is tried again with every option it had, and the rest of the game played out
+by the computer many times over, with the cards the player couldn't see dealt at random each time.
+*/
+package review
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/chrislunt/warwick/game"
+)
+
+// how many decisions a review shows
+const worstCount = 5
+
+// A Mistake is a decision where another option did better when played out
+type Mistake struct {
+	Turn        int
+	Decision    int
+	Chose       string
+	Best        string
+	ChoseWins   float64 // how often the player went on to win, a tie counting half
+	BestWins    float64
+	ChoseMargin float64 // the average VP margin at the end
+	BestMargin  float64
+}
+
+// Loss is the chance of winning the choice gave away
+func (m Mistake) Loss() float64 {
+	return m.BestWins - m.ChoseWins
+}
+
+func (m Mistake) String() string {
+	return fmt.Sprintf("turn %d, %s: you chose %q (wins %.0f%%, margin %+.1f)\n    better was %q (wins %.0f%%, margin %+.1f)",
+		m.Turn, game.DecisionName(m.Decision), m.Chose, 100*m.ChoseWins, m.ChoseMargin, m.Best, 100*m.BestWins, m.BestMargin)
+}
+
+// A Review is the worst of one player's decisions, costliest first
+type Review struct {
+	Seat      int
+	Decisions int // how many real choices they made
+	Mistakes  []Mistake
+}
+
+func (r Review) String() string {
+	output := fmt.Sprintf("-=* REVIEW OF PLAYER %d *=-\n", r.Seat)
+	if len(r.Mistakes) == 0 {
+		return output + fmt.Sprintf("Of %d decisions, none did worse than the alternatives\n", r.Decisions)
+	}
+	output += fmt.Sprintf("Of %d decisions, these cost the most:\n", r.Decisions)
+	for _, m := range r.Mistakes {
+		output += fmt.Sprintln(m)
+	}
+	return output
+}
+
+// rollout plays the game back from the log up to the decision under review, makes the alternative choice,
+// and hands the rest of the game over to the computer
+type rollout struct {
+	choices     []int
+	options     []int
+	alternative int
+	label       string // what the alternative was
+	seat        int
+	rng         *rand.Rand
+}
+
+func (r *rollout) Oversee(g *game.Game, d game.Decision) int {
+	made := len(r.options)
+	if made < len(r.choices) {
+		r.options = append(r.options, len(d.Options))
+		return r.choices[made]
+	}
+	// the decision under review: guess at the cards the seat can't see, and let the computer play from here
+	g.Determinize(r.seat, r.rng)
+	g.Overseers = [2]game.Overseer{}
+	r.label = d.Options[r.alternative].Label
+	return r.alternative
+}
+
+// the computer players that play out the rest of each game
+var computer = []game.Seat{{Name: "heuristic"}, {Name: "heuristic"}}
+
+// playOut gives the seat's result from one play out: 1 for a win, half for a tie, and the VP margin
+func playOut(start game.State, choices []int, alternative int, seat int, rng *rand.Rand) (win float64, margin int, r *rollout) {
+	g, err := game.Restore(start, computer)
+	if err != nil {
+		panic(err)
+	}
+	r = &rollout{choices: choices, alternative: alternative, seat: seat, rng: rng}
+	g.Overseers = [2]game.Overseer{r, r}
+	g.Play()
+	vp := g.VictoryPoints()
+	switch g.Winner() {
+	case seat:
+		win = 1
+	case -1:
+		win = 0.5
+	}
+	return win, vp[seat] - vp[1-seat], r
+}
+
+/*
+Game reviews the seat's decisions in a game that was played with Options.Record set.  Each option of each
+decision is played out rollouts times, using the same guesses at the hidden cards for every option so they
+are compared fairly.
+*/
+func Game(g *game.Game, seat int, rollouts int, seed int64) (*Review, error) {
+	if g.Start == nil {
+		return nil, fmt.Errorf("the game's decisions weren't recorded")
+	}
+	var choices []int
+	var decisions []game.Event
+	for _, e := range g.Events {
+		if e.Type == game.Chose {
+			choices = append(choices, e.Choice)
+			decisions = append(decisions, e)
+		}
+	}
+
+	// make sure the log plays back to the same game before trusting it, with every card where it ended up.  The
+	// hash doesn't tell the two copies of a card apart, which a game restored from a State doesn't either.
+	back, err := game.Restore(*g.Start, computer)
+	if err != nil {
+		return nil, err
+	}
+	check := &rollout{choices: choices, alternative: -1}
+	back.Overseers = [2]game.Overseer{check, check}
+	back.Play()
+	played, ended := back.Position(), g.Position()
+	if played.Hash() != ended.Hash() {
+		return nil, fmt.Errorf("the game log doesn't play back to the same game")
+	}
+
+	r := &Review{Seat: seat}
+	for k, e := range decisions {
+		if e.Seat != seat || e.Options < 2 {
+			continue
+		}
+		r.Decisions++
+		wins := make([]float64, e.Options)
+		margins := make([]float64, e.Options)
+		labels := make([]string, e.Options)
+		for i := 0; i < e.Options; i++ {
+			for n := 0; n < rollouts; n++ {
+				win, margin, played := playOut(*g.Start, choices[:k], i, seat, rand.New(rand.NewSource(seed+int64(n))))
+				labels[i] = played.label
+				wins[i] += win / float64(rollouts)
+				margins[i] += float64(margin) / float64(rollouts)
+			}
+		}
+		best := e.Choice
+		for i := range wins {
+			if wins[i] > wins[best] || (wins[i] == wins[best] && margins[i] > margins[best]) {
+				best = i
+			}
+		}
+		if best == e.Choice {
+			continue
+		}
+		r.Mistakes = append(r.Mistakes, Mistake{
+			Turn: e.Turn, Decision: e.Decision,
+			Chose: e.Label, Best: labels[best],
+			ChoseWins: wins[e.Choice], BestWins: wins[best],
+			ChoseMargin: margins[e.Choice], BestMargin: margins[best],
+		})
+	}
+	sort.SliceStable(r.Mistakes, func(i, j int) bool { return r.Mistakes[i].Loss() > r.Mistakes[j].Loss() })
+	if len(r.Mistakes) > worstCount {
+		r.Mistakes = r.Mistakes[:worstCount]
+	}
+	return r, nil
+}
@@ -0,0 +1,66 @@
+/*
+Package scenarios holds the scenario files that come with the game, built into the program so they can be
+loaded by name from anywhere.  Each file is a game.Scenario.  To add one, drop a .json file in this
+directory; its name is the file name without the .json.  A scenario can also carry a Test, a script for
+each seat and what should come of it, and RunTests plays them all, as go test does.
+*/
+package scenarios
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chrislunt/warwick/game"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// Names lists the scenarios that come with the game
+func Names() (names []string) {
+	entries, _ := files.ReadDir(".")
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return
+}
+
+// Get loads a scenario that comes with the game by name
+func Get(name string) (*game.Scenario, error) {
+	data, err := files.ReadFile(name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("there's no scenario %q, try one of %s", name, strings.Join(Names(), ", "))
+	}
+	s, err := game.ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if s.Name == "" {
+		s.Name = name
+	}
+	return s, nil
+}
+
+// Load loads a scenario by name, or from a file if there's a file by that name
+func Load(spec string) (*game.Scenario, error) {
+	if _, err := os.Stat(spec); err == nil {
+		return game.LoadScenario(spec)
+	}
+	return Get(spec)
+}
+
+// All loads every scenario that comes with the game
+func All() (list []*game.Scenario, err error) {
+	for _, name := range Names() {
+		s, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return
+}
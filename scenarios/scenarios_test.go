@@ -0,0 +1,36 @@
+package scenarios
+
+import (
+	"testing"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// TestScenarios plays the test of every scenario that has one, each as its own subtest
+func TestScenarios(t *testing.T) {
+	game.LogLevel, player.LogLevel = 0, 0
+	for _, name := range Names() {
+		test, err := GetTest(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			for _, failure := range test.Run() {
+				t.Errorf("%s", failure)
+			}
+		})
+	}
+}
+
+// TestParse makes sure every scenario that comes with the game can be set up
+func TestParse(t *testing.T) {
+	for _, name := range Names() {
+		if _, err := Get(name); err != nil {
+			t.Error(err)
+		}
+	}
+}
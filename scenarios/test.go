@@ -0,0 +1,383 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+/*
+A Test plays a scenario with a script for each seat and checks what comes of it.  It lives in the same file
+as the scenario, next to the setup:
+
+	"seed": 1,
+	"script": [
+		["build Trading Post", "discard Mage", "draw Mage from the discard"],
+		[]
+	],
+	"expect": [
+		{"turn": 1, "seat": 0, "players": [{"tableau": ["Trading Post"], "hand": 5}], "discard": 0, "top": "none"},
+		{"ending": "stock"}
+	]
+
+Each seat's script is the options it takes, by label and in order, from its first decision on.  A seat
+plays as its own player once its script runs out, so a script only has to go as far as the part under test.
+The rest of the stock is shuffled from the seed, which is 1 if it's left out.  With "check": true the game
+is also checked with game.Check at every decision, which is how the fuzzer saves the failures it finds.
+*/
+type Test struct {
+	Scenario *game.Scenario
+	Seed     int64
+	Script   [2][]string
+	Expect   []Expect
+	Check    bool `json:",omitempty"`
+}
+
+/*
+Expect is what the game should look like once a seat's part of a turn is over, or at the end of the game if
+there's no turn.  Only the things given are checked.
+*/
+type Expect struct {
+	Turn    int `json:",omitempty"`
+	Seat    int `json:",omitempty"`
+	Players [2]PlayerExpect
+	Stock   *int   `json:",omitempty"` // how many cards are left in the stock
+	Discard *int   `json:",omitempty"` // and in the discard pile
+	Trash   *int   `json:",omitempty"` // and in the trash
+	Top     string `json:",omitempty"` // the top card of the discard pile, "none" for an empty pile
+	Over    *bool  `json:",omitempty"`
+	Ending  string `json:",omitempty"` // why the game ended: stock, built, turn cap, turn limit or forfeit
+	Winner  *int   `json:",omitempty"` // -1 for a tie
+}
+
+// PlayerExpect is what one player's side of the table should look like
+type PlayerExpect struct {
+	Tableau []string `json:",omitempty"` // every card built, in any order
+	Storage []string `json:",omitempty"` // the cards in storage, in any order
+	Hand    *int     `json:",omitempty"` // how many cards are held
+	Holding []string `json:",omitempty"` // the cards held, in any order
+	VP      *int     `json:",omitempty"`
+}
+
+// a script plays a seat's options by label, by what the seat can see, then hands the seat back to its player
+type script struct {
+	labels []string
+	seat   int
+	check  func() error // game.Check, to run at every decision, nil if the test doesn't check
+	done   func()       // gives the seat back to its player
+	err    error
+}
+
+func (s *script) Choose(view *player.PlayerView, d game.Decision) int {
+	turn := view.Clock.Turn
+	if s.check != nil && s.err == nil {
+		if err := s.check(); err != nil {
+			s.err = fmt.Errorf("turn %d, %s: %v", turn, d, err)
+		}
+	}
+	label := s.labels[0]
+	s.labels = s.labels[1:]
+	if len(s.labels) == 0 {
+		s.done()
+	}
+	var offered []string
+	for i, option := range d.Options {
+		if strings.EqualFold(option.Label, label) {
+			return i
+		}
+		offered = append(offered, option.Label)
+	}
+	// the script is out of step with the game, so stop the game here
+	if s.err != nil {
+		return 0
+	}
+	s.err = fmt.Errorf("turn %d, %s: there's no option %q, only %s", turn, d, label, strings.Join(offered, "; "))
+	d.Forfeit("the script went wrong")
+	return 0
+}
+
+// ParseTest reads a test from the JSON of a scenario file.  It's nil if the file doesn't have one.
+func ParseTest(data []byte) (*Test, error) {
+	s, err := game.ParseScenario(data)
+	if err != nil {
+		return nil, err
+	}
+	var t Test
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if len(t.Expect) == 0 {
+		return nil, nil
+	}
+	if t.Seed == 0 {
+		t.Seed = 1
+	}
+	t.Scenario = s
+	return &t, nil
+}
+
+/*
+Run plays the test, giving back everything that didn't come out as expected.  A panic in the engine is a
+failure like any other.
+*/
+func (t *Test) Run() (failures []string) {
+	g := game.New(game.Options{Seed: t.Seed, Scenario: t.Scenario})
+	var scripts []*script
+	for seat, labels := range t.Script {
+		if len(labels) > 0 {
+			seat := seat
+			s := &script{labels: labels, seat: seat, done: func() { g.Choosers[seat] = nil }}
+			if t.Check {
+				s.check = g.Check
+			}
+			g.Choosers[seat] = s
+			scripts = append(scripts, s)
+		}
+	}
+	fail := func(format string, a ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, a...))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fail("turn %d, player %d: the engine panicked: %v", g.Turn, g.ToMove, r)
+		}
+	}()
+	checked := make([]bool, len(t.Expect))
+	for !g.Over {
+		seat := g.ToMove
+		g.PlayTurn()
+		for _, s := range scripts {
+			if s.err != nil {
+				fail("%v", s.err)
+				return
+			}
+		}
+		if t.Check {
+			if err := g.Check(); err != nil {
+				fail("turn %d, player %d: %v", g.Turn, seat, err)
+				return
+			}
+		}
+		for i, e := range t.Expect {
+			if e.Turn != 0 && e.Turn == g.Turn && e.Seat == seat && !checked[i] {
+				checked[i] = true
+				for _, f := range e.check(g) {
+					fail("turn %d, player %d: %s", e.Turn, e.Seat, f)
+				}
+			}
+		}
+	}
+	for i, e := range t.Expect {
+		if e.Turn == 0 {
+			for _, f := range e.check(g) {
+				fail("at the end: %s", f)
+			}
+		} else if !checked[i] {
+			fail("the game ended on turn %d, before player %d's turn %d", g.Turn, e.Seat, e.Turn)
+		}
+	}
+	for _, s := range scripts {
+		if len(s.labels) > 0 {
+			fail("player %d's script has %d options left at the end of the game", s.seat, len(s.labels))
+		}
+	}
+	return
+}
+
+// Save writes the test out as a scenario file, with the setup, the script and what to expect side by side
+func (t *Test) Save(path string) error {
+	setup, err := json.Marshal(t.Scenario)
+	if err != nil {
+		return err
+	}
+	var file map[string]interface{}
+	if err := json.Unmarshal(setup, &file); err != nil {
+		return err
+	}
+	file["Seed"], file["Script"], file["Expect"] = t.Seed, t.Script, t.Expect
+	if t.Check {
+		file["Check"] = true
+	}
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// same compares lists of card names in any order
+func same(want []string, cards []*card.Card) bool {
+	var got []string
+	for _, c := range cards {
+		if c != nil {
+			got = append(got, strings.ToLower(c.Name))
+		}
+	}
+	lower := make([]string, len(want))
+	for i, name := range want {
+		lower[i] = strings.ToLower(name)
+	}
+	sort.Strings(got)
+	sort.Strings(lower)
+	return strings.Join(got, ",") == strings.Join(lower, ",")
+}
+
+func names(cards []*card.Card) string {
+	var list []string
+	for _, c := range cards {
+		if c != nil {
+			list = append(list, c.Name)
+		}
+	}
+	if len(list) == 0 {
+		return "nothing"
+	}
+	return strings.Join(list, ", ")
+}
+
+// check gives what in the game isn't as expected
+func (e Expect) check(g *game.Game) (failures []string) {
+	fail := func(format string, a ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, a...))
+	}
+	count := func(what string, want *int, got int) {
+		if want != nil && *want != got {
+			fail("%s is %d, expected %d", what, got, *want)
+		}
+	}
+	vp := g.VictoryPoints()
+	for id, want := range e.Players {
+		p := g.Players[id]
+		var built []*card.Card
+		for kind := 0; kind <= 9; kind++ {
+			if stack := p.Tableau.Stack[kind]; stack != nil {
+				built = append(built, stack.Cards...)
+			}
+		}
+		if want.Tableau != nil && !same(want.Tableau, built) {
+			fail("player %d has built %s, expected %s", id, names(built), strings.Join(want.Tableau, ", "))
+		}
+		if want.Storage != nil && !same(want.Storage, p.Tableau.Storage) {
+			fail("player %d has stored %s, expected %s", id, names(p.Tableau.Storage), strings.Join(want.Storage, ", "))
+		}
+		if want.Holding != nil && !same(want.Holding, p.Hand.Cards) {
+			fail("player %d holds %s, expected %s", id, names(p.Hand.Cards), strings.Join(want.Holding, ", "))
+		}
+		count(fmt.Sprintf("player %d's hand", id), want.Hand, p.Hand.Count)
+		count(fmt.Sprintf("player %d's VP", id), want.VP, vp[id])
+	}
+	count("the stock", e.Stock, g.Stock.PullPos+1)
+	count("the discard pile", e.Discard, g.DiscardPile.PullPos+1)
+	count("the trash", e.Trash, g.Trash.PullPos+1)
+	if e.Top != "" {
+		top := "none"
+		if g.DiscardPile.PullPos >= 0 {
+			top = g.DiscardPile.Cards[g.DiscardPile.PullPos].Name
+		}
+		if !strings.EqualFold(top, e.Top) {
+			fail("the top of the discard pile is %s, expected %s", top, e.Top)
+		}
+	}
+	if e.Over != nil && *e.Over != g.Over {
+		fail("the game being over is %t, expected %t", g.Over, *e.Over)
+	}
+	if e.Ending != "" && e.Ending != game.EndingName(g.Ending) {
+		fail("the game ended by %s, expected %s", game.EndingName(g.Ending), e.Ending)
+	}
+	if e.Winner != nil && *e.Winner != g.Winner() {
+		fail("the winner is %d, expected %d", g.Winner(), *e.Winner)
+	}
+	return
+}
+
+// A Result is how one scenario's test went
+type Result struct {
+	Name     string
+	Failures []string
+}
+
+// Results are the outcomes of running the scenario tests
+type Results []Result
+
+// Failed counts the tests that didn't pass
+func (list Results) Failed() (n int) {
+	for _, r := range list {
+		if len(r.Failures) > 0 {
+			n++
+		}
+	}
+	return
+}
+
+func (list Results) String() string {
+	output := ""
+	for _, r := range list {
+		if len(r.Failures) == 0 {
+			output += fmt.Sprintf("ok   %s\n", r.Name)
+			continue
+		}
+		output += fmt.Sprintf("FAIL %s\n", r.Name)
+		for _, f := range r.Failures {
+			output += fmt.Sprintf("     %s\n", f)
+		}
+	}
+	return output + fmt.Sprintf("%d scenarios tested, %d failed\n", len(list), list.Failed())
+}
+
+/*
+RunTests runs the test of every scenario that comes with the game and has one.  A Go test can call it and
+fail on Failed, or run one scenario's test with GetTest.
+*/
+func RunTests() (results Results, err error) {
+	for _, name := range Names() {
+		t, err := GetTest(name)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			results = append(results, Result{Name: name, Failures: t.Run()})
+		}
+	}
+	return
+}
+
+// LoadTest loads the test of a scenario by name, or from a file if there's a file by that name
+func LoadTest(spec string) (*Test, error) {
+	data, err := os.ReadFile(spec)
+	if os.IsNotExist(err) {
+		return GetTest(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", spec, err)
+	}
+	if t != nil && t.Scenario.Name == "" {
+		t.Scenario.Name = spec
+	}
+	return t, nil
+}
+
+// GetTest loads the test of a scenario that comes with the game, nil if it doesn't have one
+func GetTest(name string) (*Test, error) {
+	data, err := files.ReadFile(name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("there's no scenario %q, try one of %s", name, strings.Join(Names(), ", "))
+	}
+	t, err := ParseTest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if t != nil && t.Scenario.Name == "" {
+		t.Scenario.Name = name
+	}
+	return t, nil
+}
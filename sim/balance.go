@@ -0,0 +1,181 @@
+/*
+Package sim plays lots of games between computer players and reports on what happened, so we can see
+which cards, strategies and rules are pulling their weight.
+*/
+package sim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/game"
+)
+
+// Run plays count games, seeded from seed, seed+1 and so on, and hands each finished game to visit.
+// setup may adjust the options before each game is dealt.
+func Run(count int, seed int64, setup func(*game.Options), visit func(*game.Game)) {
+	for i := 0; i < count; i++ {
+		options := game.Options{Seed: seed + int64(i)}
+		if setup != nil {
+			setup(&options)
+		}
+		g := game.New(options)
+		g.Play()
+		visit(g)
+	}
+}
+
+// CardStats is what we learned about one entry in card.Deck
+type CardStats struct {
+	Card        card.Card
+	Built       int     // how many times it was built, counting both copies
+	GamesBuilt  int     // how many games it was built in at least once
+	Stolen      int     // how many times a soldier took it
+	turnTotal   int     // sum of the turns it was built on, for the average
+	Held        int     // player-games that ended with it on top of a stack
+	HeldWins    float64 // wins for those player-games, a tie counts as half
+	NotHeld     int
+	NotHeldWins float64
+}
+
+// AverageTurn is the mean turn the card was built on, or 0 if it never was
+func (s CardStats) AverageTurn() float64 {
+	if s.Built == 0 {
+		return 0
+	}
+	return float64(s.turnTotal) / float64(s.Built)
+}
+
+// WinRateHeld is how often a player won when the card was in their final tableau
+func (s CardStats) WinRateHeld() float64 {
+	if s.Held == 0 {
+		return 0
+	}
+	return s.HeldWins / float64(s.Held)
+}
+
+// WinRateNotHeld is how often a player won without the card in their final tableau
+func (s CardStats) WinRateNotHeld() float64 {
+	if s.NotHeld == 0 {
+		return 0
+	}
+	return s.NotHeldWins / float64(s.NotHeld)
+}
+
+// Balance collects CardStats for every entry in card.Deck across many games
+type Balance struct {
+	Games int
+	Cards []CardStats
+}
+
+func NewBalance() *Balance {
+	b := &Balance{Cards: make([]CardStats, len(card.Deck))}
+	for i, c := range card.Deck {
+		b.Cards[i].Card = c
+	}
+	return b
+}
+
+// Add folds a finished game into the report
+func (b *Balance) Add(g *game.Game) {
+	b.Games++
+	builtThisGame := make([]bool, len(card.Deck))
+	for _, e := range g.Events {
+		if e.Card == nil {
+			continue
+		}
+		index := card.DeckIndex(e.Card)
+		switch e.Type {
+		case game.Built:
+			b.Cards[index].Built++
+			b.Cards[index].turnTotal += e.Turn
+			builtThisGame[index] = true
+		case game.Stole:
+			b.Cards[index].Stolen++
+		}
+	}
+	for index, built := range builtThisGame {
+		if built {
+			b.Cards[index].GamesBuilt++
+		}
+	}
+
+	// only the top of each stack counts as in the tableau, since the cards underneath have no effect
+	winner := g.Winner()
+	for seat, p := range g.Players {
+		win := 0.0
+		if winner == seat {
+			win = 1
+		} else if winner == -1 {
+			win = 0.5
+		}
+		held := make([]bool, len(card.Deck))
+		for kind := 0; kind <= 9; kind++ {
+			if top := p.TopCard(kind); top != nil {
+				held[card.DeckIndex(top)] = true
+			}
+		}
+		for index := range b.Cards {
+			if held[index] {
+				b.Cards[index].Held++
+				b.Cards[index].HeldWins += win
+			} else {
+				b.Cards[index].NotHeld++
+				b.Cards[index].NotHeldWins += win
+			}
+		}
+	}
+}
+
+var balanceHeader = []string{"card", "kind", "cost", "built", "built_per_game", "games_built_pct", "stolen", "win_rate_held", "win_rate_not_held", "avg_turn_built"}
+
+func (b *Balance) rows() (rows [][]string) {
+	games := float64(b.Games)
+	if games == 0 {
+		games = 1
+	}
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+	for _, s := range b.Cards {
+		rows = append(rows, []string{
+			s.Card.Name,
+			card.KindName(s.Card.Kind),
+			strconv.Itoa(s.Card.Cost),
+			strconv.Itoa(s.Built),
+			f(float64(s.Built) / games),
+			f(100 * float64(s.GamesBuilt) / games),
+			strconv.Itoa(s.Stolen),
+			f(s.WinRateHeld()),
+			f(s.WinRateNotHeld()),
+			f(s.AverageTurn()),
+		})
+	}
+	return
+}
+
+// WriteCSV writes one row per deck entry, with a header
+func (b *Balance) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write(balanceHeader)
+	out.WriteAll(b.rows())
+	return out.Error()
+}
+
+func (b Balance) String() string {
+	games := float64(b.Games)
+	if games == 0 {
+		games = 1
+	}
+	output := fmt.Sprintf("%d games\n", b.Games)
+	output += fmt.Sprintf("%-14s %-14s %4s %7s %8s %6s %8s %8s %6s\n", "card", "kind", "cost", "built", "games%", "stolen", "win held", "win not", "turn")
+	for _, s := range b.Cards {
+		output += fmt.Sprintf("%-14s %-14s %4d %7d %7.1f%% %6d %8.3f %8.3f %6.1f\n",
+			s.Card.Name, card.KindName(s.Card.Kind), s.Card.Cost, s.Built,
+			100*float64(s.GamesBuilt)/games, s.Stolen, s.WinRateHeld(), s.WinRateNotHeld(), s.AverageTurn())
+	}
+	return output
+}
@@ -0,0 +1,154 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/chrislunt/warwick/card"
+	"github.com/chrislunt/warwick/game"
+)
+
+// the measurements we take from one game, so the same deal can be compared under two sets of rules
+type sample struct {
+	turns      float64
+	margin     float64 // how decisive the game was, the gap in VP
+	firstWins  float64 // 1 if the first player won, half for a tie
+	kindBuilds [10]float64
+}
+
+func measure(g *game.Game) (s sample) {
+	s.turns = float64(g.Turn)
+	vp := g.VictoryPoints()
+	s.margin = math.Abs(float64(vp[0] - vp[1]))
+	switch g.Winner() {
+	case 0:
+		s.firstWins = 1
+	case -1:
+		s.firstWins = 0.5
+	}
+	for _, e := range g.Events {
+		if e.Type == game.Built {
+			s.kindBuilds[e.Card.Kind]++
+		}
+	}
+	return
+}
+
+// A Difference is one measurement compared across the two sets of rules
+type Difference struct {
+	Name  string
+	MeanA float64
+	MeanB float64
+	P     float64 // the chance of a difference this big if the rules made no difference
+	Holm  float64 // P adjusted for testing all the differences at once
+}
+
+// Significant goes by the adjusted p, since with a dozen measurements one of them would pass at 0.05 by
+// chance most runs
+func (d Difference) Significant() bool {
+	return d.Holm < 0.05
+}
+
+// Comparison is the result of playing the same deals under two sets of rules
+type Comparison struct {
+	A           game.Rules
+	B           game.Rules
+	Games       int
+	Differences []Difference
+}
+
+// CompareRules plays games under rules a and b with the same seeds, so each deal is played both ways.
+func CompareRules(a, b game.Rules, games int, seed int64) *Comparison {
+	c := &Comparison{A: a, B: b, Games: games}
+	var samplesA, samplesB []sample
+	Run(games, seed, func(options *game.Options) {
+		options.Rules = &a
+	}, func(g *game.Game) {
+		samplesA = append(samplesA, measure(g))
+	})
+	Run(games, seed, func(options *game.Options) {
+		options.Rules = &b
+	}, func(g *game.Game) {
+		samplesB = append(samplesB, measure(g))
+	})
+
+	add := func(name string, value func(sample) float64) {
+		d := Difference{Name: name}
+		diffs := make([]float64, games)
+		for i := range samplesA {
+			d.MeanA += value(samplesA[i])
+			d.MeanB += value(samplesB[i])
+			diffs[i] = value(samplesB[i]) - value(samplesA[i])
+		}
+		d.MeanA /= float64(games)
+		d.MeanB /= float64(games)
+		d.P = pairedP(diffs)
+		c.Differences = append(c.Differences, d)
+	}
+	add("game length (turns)", func(s sample) float64 { return s.turns })
+	add("decisiveness (VP margin)", func(s sample) float64 { return s.margin })
+	add("first player win rate", func(s sample) float64 { return s.firstWins })
+	for kind := 0; kind <= 9; kind++ {
+		kind := kind
+		add(fmt.Sprintf("%s builds per game", card.KindName(kind)), func(s sample) float64 { return s.kindBuilds[kind] })
+	}
+	holm(c.Differences)
+	return c
+}
+
+// holm adjusts the p values with the Holm-Bonferroni method: the smallest is multiplied by how many
+// there are, the next by one fewer, and so on, never going back down
+func holm(differences []Difference) {
+	order := make([]int, len(differences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return differences[order[i]].P < differences[order[j]].P })
+	adjusted := 0.0
+	for rank, i := range order {
+		adjusted = math.Max(adjusted, math.Min(1, float64(len(differences)-rank)*differences[i].P))
+		differences[i].Holm = adjusted
+	}
+}
+
+// pairedP is a two-sided paired t-test on the differences.  With the hundreds of games we play the t
+// distribution is close enough to normal that we use the normal tail.
+func pairedP(diffs []float64) float64 {
+	n := float64(len(diffs))
+	if n < 2 {
+		return 1
+	}
+	mean := 0.0
+	for _, d := range diffs {
+		mean += d
+	}
+	mean /= n
+	variance := 0.0
+	for _, d := range diffs {
+		variance += (d - mean) * (d - mean)
+	}
+	variance /= n - 1
+	if variance == 0 {
+		if mean == 0 {
+			return 1
+		}
+		return 0
+	}
+	t := mean / math.Sqrt(variance/n)
+	return math.Erfc(math.Abs(t) / math.Sqrt2)
+}
+
+func (c Comparison) String() string {
+	output := fmt.Sprintf("A: %s\nB: %s\n%d games each, same deals\n", c.A, c.B, c.Games)
+	output += fmt.Sprintf("%-30s %9s %9s %9s %8s %8s\n", "", "A", "B", "B-A", "p", "holm p")
+	for _, d := range c.Differences {
+		flag := ""
+		if d.Significant() {
+			flag = " *"
+		}
+		output += fmt.Sprintf("%-30s %9.3f %9.3f %+9.3f %8.4f %8.4f%s\n", d.Name, d.MeanA, d.MeanB, d.MeanB-d.MeanA, d.P, d.Holm, flag)
+	}
+	output += fmt.Sprintf("* significant at p < 0.05 after the Holm correction for the %d comparisons\n", len(c.Differences))
+	return output
+}
@@ -0,0 +1,256 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+	"github.com/chrislunt/warwick/scenarios"
+)
+
+// A Step is one decision of a fuzzed game, and the option taken
+type Step struct {
+	Turn     int
+	Seat     int
+	Decision int
+	Option   int
+	Label    string
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("turn %d: player %d %s: %s", s.Turn, s.Seat, game.DecisionName(s.Decision), s.Label)
+}
+
+// A Failure is a game that broke one of the engine's rules, with the options that got it there
+type Failure struct {
+	Seed    int64
+	Actions []int  // the option taken at each decision, 0 once the list runs out
+	Steps   []Step // the decisions made, up to where it broke
+	Err     error
+}
+
+// Rule is the rule broken, the start of the error up to the colon, or "panic"
+func (f *Failure) Rule() string {
+	return rule(f.Err)
+}
+
+func rule(err error) string {
+	name, _, _ := strings.Cut(err.Error(), ":")
+	return name
+}
+
+func (f *Failure) String() string {
+	output := fmt.Sprintf("seed %d, after %d decisions: %v\n", f.Seed, len(f.Steps), f.Err)
+	for _, step := range f.Steps {
+		output += fmt.Sprintf("    %s\n", step)
+	}
+	return output
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("seed %d, after %d decisions: %v", f.Seed, len(f.Steps), f.Err)
+}
+
+// fuzzer takes the options it's given, checking the game at every decision
+type fuzzer struct {
+	actions []int
+	steps   []Step
+	check   func() error // game.Check on the game being fuzzed
+}
+
+// broken is thrown to stop a game that's failed a check
+type broken struct{ err error }
+
+func (f *fuzzer) Choose(view *player.PlayerView, d game.Decision) int {
+	if err := f.check(); err != nil {
+		panic(broken{err})
+	}
+	option := 0
+	if n := len(f.steps); n < len(f.actions) {
+		option = f.actions[n] % len(d.Options)
+	}
+	f.steps = append(f.steps, Step{Turn: view.Clock.Turn, Seat: d.Seat, Decision: d.Type, Option: option, Label: d.Options[option].Label})
+	return option
+}
+
+// playActions plays a game from the seed taking the given options, and gives back the first rule it breaks
+func playActions(seed int64, actions []int) (steps []Step, err error) {
+	g := game.New(game.Options{Seed: seed})
+	f := &fuzzer{actions: actions, check: g.Check}
+	g.Choosers = [2]game.Chooser{f, f}
+	defer func() {
+		if r := recover(); r != nil {
+			steps = f.steps
+			if b, ok := r.(broken); ok {
+				err = b.err
+			} else {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}
+	}()
+	for !g.Over {
+		seat := g.ToMove
+		g.PlayTurn()
+		if err := g.Check(); err != nil {
+			return f.steps, err
+		}
+		if p := g.Players[seat]; !g.Over && p.Hand.Count > p.Hand.Limit {
+			return f.steps, fmt.Errorf("hands: player %d ended the turn holding %d cards, over the limit of %d", seat, p.Hand.Count, p.Hand.Limit)
+		}
+	}
+	if g.Ending == game.NotOver {
+		return f.steps, fmt.Errorf("endings: the game is over without a reason")
+	}
+	return f.steps, nil
+}
+
+/*
+CheckGame plays a game from the seed with every decision taken from actions, one byte each, checking the
+game after every decision and every turn.  It gives back a *Failure for the first rule broken, or nil.  It's
+shaped for Go's fuzzing, to use from a test as
+
+	f.Fuzz(func(t *testing.T, seed int64, actions []byte) {
+		if err := sim.CheckGame(seed, actions); err != nil {
+			t.Fatal(err)
+		}
+	})
+*/
+func CheckGame(seed int64, actions []byte) error {
+	options := make([]int, len(actions))
+	for i, b := range actions {
+		options[i] = int(b)
+	}
+	if f := check(seed, options); f != nil {
+		return f
+	}
+	return nil
+}
+
+// check plays the actions, and keeps the options actually taken if it fails
+func check(seed int64, actions []int) *Failure {
+	steps, err := playActions(seed, actions)
+	if err == nil {
+		return nil
+	}
+	f := &Failure{Seed: seed, Steps: steps, Err: err}
+	for _, step := range steps {
+		f.Actions = append(f.Actions, step.Option)
+	}
+	// options of 0 at the end are what it takes anyway
+	for len(f.Actions) > 0 && f.Actions[len(f.Actions)-1] == 0 {
+		f.Actions = f.Actions[:len(f.Actions)-1]
+	}
+	return f
+}
+
+// smaller is true when a is a simpler failure than b: fewer options given, or the same number but lower
+func smaller(a, b *Failure) bool {
+	if len(a.Actions) != len(b.Actions) {
+		return len(a.Actions) < len(b.Actions)
+	}
+	sum := 0
+	for i := range a.Actions {
+		sum += a.Actions[i] - b.Actions[i]
+	}
+	return sum < 0
+}
+
+/*
+Shrink looks for the simplest list of options that still breaks the same rule: it drops options and sets
+them to the first one, keeping anything that fails the same way, until nothing more can go.
+*/
+func Shrink(f *Failure) *Failure {
+	best := f
+	try := func(actions []int) bool {
+		if next := check(best.Seed, actions); next != nil && next.Rule() == best.Rule() && smaller(next, best) {
+			best = next
+			return true
+		}
+		return false
+	}
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := len(best.Actions) - 1; i >= 0; i-- {
+			if i >= len(best.Actions) {
+				continue
+			}
+			dropped := append(append([]int(nil), best.Actions[:i]...), best.Actions[i+1:]...)
+			if try(dropped) {
+				shrunk = true
+				continue
+			}
+			if best.Actions[i] > 0 {
+				first := append([]int(nil), best.Actions...)
+				first[i] = 0
+				shrunk = try(first) || shrunk
+			}
+		}
+	}
+	return best
+}
+
+// Test turns a failure into a scenario test that plays it again: the deal is written out card by card, and
+// each seat's script is its decisions up to where the game broke
+func (f *Failure) Test(name string) *scenarios.Test {
+	g := game.New(game.Options{Seed: f.Seed})
+	t := &scenarios.Test{Scenario: g.Scenario(name, fmt.Sprint(f.Err)), Seed: f.Seed, Check: true}
+	for seat := range t.Script {
+		t.Script[seat] = []string{}
+	}
+	last := Step{Turn: 1}
+	for _, step := range f.Steps {
+		t.Script[step.Seat] = append(t.Script[step.Seat], step.Label)
+		last = step
+	}
+	t.Expect = []scenarios.Expect{{Turn: last.Turn, Seat: last.Seat}}
+	return t
+}
+
+// A FuzzReport is what a run of Fuzz found, the simplest failure of each rule broken
+type FuzzReport struct {
+	Games    int
+	Failures []*Failure
+	Counts   map[string]int // how many games broke each rule
+}
+
+func (r FuzzReport) String() string {
+	if len(r.Failures) == 0 {
+		return fmt.Sprintf("%d games of random play, no rules broken\n", r.Games)
+	}
+	output := ""
+	for _, f := range r.Failures {
+		output += fmt.Sprintf("%s, broken in %d of %d games, shrunk to:\n%s", f.Rule(), r.Counts[f.Rule()], r.Games, f.String())
+	}
+	return output
+}
+
+/*
+Fuzz plays games of random options from random seeds, checking the engine's rules at every step.  The first
+failure of each rule is shrunk.  length is how many random options each game gets, the first option is
+taken after that.
+*/
+func Fuzz(games int, seed int64, length int) FuzzReport {
+	rng := rand.New(rand.NewSource(seed))
+	report := FuzzReport{Games: games, Counts: make(map[string]int)}
+	for i := 0; i < games; i++ {
+		actions := make([]int, length)
+		for j := range actions {
+			actions[j] = rng.Intn(256)
+		}
+		f := check(rng.Int63(), actions)
+		if f == nil {
+			continue
+		}
+		if report.Counts[f.Rule()] == 0 {
+			report.Failures = append(report.Failures, Shrink(f))
+		}
+		report.Counts[f.Rule()]++
+	}
+	sort.Slice(report.Failures, func(i, j int) bool {
+		return report.Counts[report.Failures[i].Rule()] > report.Counts[report.Failures[j].Rule()]
+	})
+	return report
+}
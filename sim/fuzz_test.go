@@ -0,0 +1,42 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+	"github.com/chrislunt/warwick/scenarios"
+)
+
+/*
+FuzzGame plays games with Go's fuzzer choosing the deal and every decision, checking the engine's rules
+all the way through with CheckGame.  The seeds to start from are the deals of the scenario tests that come
+with the game, each played with the first option every time and with a spread of others.  go test plays
+just those, go test -fuzz FuzzGame goes looking for more.
+*/
+func FuzzGame(f *testing.F) {
+	game.LogLevel, player.LogLevel = 0, 0
+	seeds := map[int64]bool{1: true}
+	for _, name := range scenarios.Names() {
+		t, err := scenarios.GetTest(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		if t != nil {
+			seeds[t.Seed] = true
+		}
+	}
+	spread := make([]byte, 256)
+	for i := range spread {
+		spread[i] = byte(i * 7)
+	}
+	for seed := range seeds {
+		f.Add(seed, []byte{})
+		f.Add(seed, spread)
+	}
+	f.Fuzz(func(t *testing.T, seed int64, actions []byte) {
+		if err := CheckGame(seed, actions); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
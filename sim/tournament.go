@@ -0,0 +1,230 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/chrislunt/warwick/game"
+)
+
+// A Match is one game of a tournament.  Seats hold indexes into the entrant list, in seat order.
+type Match struct {
+	Seed   int64
+	Seats  [2]int
+	VP     [2]int
+	Winner int // the entrant index of the winner, -1 for a tie
+	Turns  int
+}
+
+// A Rating is one line of the leaderboard.  Low and High are a 95% confidence interval on the Elo.
+type Rating struct {
+	Name   string
+	Elo    float64
+	Low    float64
+	High   float64
+	Games  int
+	Score  float64 // wins plus half the ties
+	Before float64 `json:",omitempty"` // the Elo from an earlier run, when comparing
+}
+
+// Tournament is everything a run produced, which is what gets written to the results file
+type Tournament struct {
+	Games       int // games per pairing and seat order
+	Seed        int64
+	Entrants    []string
+	Matches     []Match
+	Leaderboard []Rating
+}
+
+// RunTournament plays every pair of seats against each other in both seat orders.  Each seed is
+// played by both orders, so neither side gets the luckier deals.
+func RunTournament(seats []game.Seat, games int, seed int64) *Tournament {
+	t := &Tournament{Games: games, Seed: seed}
+	used := make(map[string]int)
+	for _, seat := range seats {
+		name := seat.Name
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, used[name])
+		}
+		t.Entrants = append(t.Entrants, name)
+	}
+	for a := range seats {
+		for b := range seats {
+			if a == b {
+				continue
+			}
+			pairing := [2]int{a, b}
+			Run(games, seed, func(options *game.Options) {
+				options.Seats = []game.Seat{seats[a], seats[b]}
+			}, func(g *game.Game) {
+				m := Match{Seed: g.Seed(), Seats: pairing, Turns: g.Turn, Winner: -1}
+				vp := g.VictoryPoints()
+				m.VP = [2]int{vp[0], vp[1]}
+				if w := g.Winner(); w != -1 {
+					m.Winner = pairing[w]
+				}
+				t.Matches = append(t.Matches, m)
+			})
+		}
+	}
+	t.Leaderboard = Leaderboard(t.Entrants, t.Matches, 200, seed)
+	return t
+}
+
+// Leaderboard rates the entrants from their matches, best first.  The confidence interval comes from
+// rating resamples of the matches, so it widens when there are only a few games.
+func Leaderboard(names []string, matches []Match, resamples int, seed int64) []Rating {
+	elo := eloRatings(len(names), matches)
+	board := make([]Rating, len(names))
+	for i, name := range names {
+		board[i] = Rating{Name: name, Elo: elo[i], Low: elo[i], High: elo[i]}
+	}
+	for _, m := range matches {
+		for _, entrant := range m.Seats {
+			board[entrant].Games++
+			if m.Winner == entrant {
+				board[entrant].Score++
+			} else if m.Winner == -1 {
+				board[entrant].Score += 0.5
+			}
+		}
+	}
+
+	if resamples > 0 && len(matches) > 0 {
+		rng := rand.New(rand.NewSource(seed))
+		samples := make([][]float64, len(names))
+		resample := make([]Match, len(matches))
+		for r := 0; r < resamples; r++ {
+			for i := range resample {
+				resample[i] = matches[rng.Intn(len(matches))]
+			}
+			for i, e := range eloRatings(len(names), resample) {
+				samples[i] = append(samples[i], e)
+			}
+		}
+		for i := range board {
+			sort.Float64s(samples[i])
+			board[i].Low = samples[i][int(0.025*float64(resamples-1))]
+			board[i].High = samples[i][int(0.975*float64(resamples-1))]
+		}
+	}
+
+	sort.SliceStable(board, func(i, j int) bool {
+		return board[i].Elo > board[j].Elo
+	})
+	return board
+}
+
+// eloRatings fits a Bradley-Terry model to the results, scaled like Elo with an average of 1500.
+// Every pair is given one extra tied game, so someone who wins everything still gets a finite rating.
+func eloRatings(count int, matches []Match) []float64 {
+	games := make([][]float64, count) // games[i][j] is how many times i and j played
+	score := make([]float64, count)
+	for i := range games {
+		games[i] = make([]float64, count)
+		for j := range games[i] {
+			if i != j {
+				games[i][j] = 1
+				score[i] += 0.5
+			}
+		}
+	}
+	for _, m := range matches {
+		a, b := m.Seats[0], m.Seats[1]
+		games[a][b]++
+		games[b][a]++
+		if m.Winner == -1 {
+			score[a] += 0.5
+			score[b] += 0.5
+		} else {
+			score[m.Winner]++
+		}
+	}
+
+	strength := make([]float64, count)
+	for i := range strength {
+		strength[i] = 1
+	}
+	for iteration := 0; iteration < 200; iteration++ {
+		next := make([]float64, count)
+		for i := range strength {
+			denominator := 0.0
+			for j := range strength {
+				if i != j {
+					denominator += games[i][j] / (strength[i] + strength[j])
+				}
+			}
+			next[i] = score[i] / denominator
+		}
+		strength = next
+	}
+
+	elo := make([]float64, count)
+	mean := 0.0
+	for i, s := range strength {
+		elo[i] = 400 * math.Log10(s)
+		mean += elo[i]
+	}
+	mean /= float64(count)
+	for i := range elo {
+		elo[i] += 1500 - mean
+	}
+	return elo
+}
+
+// Save writes the tournament as JSON
+func (t *Tournament) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadTournament reads back a file written by Save
+func LoadTournament(path string) (*Tournament, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tournament{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return t, nil
+}
+
+// CompareTo fills in Before for every entrant that was also in the earlier tournament
+func (t *Tournament) CompareTo(earlier *Tournament) {
+	before := make(map[string]float64)
+	for _, r := range earlier.Leaderboard {
+		before[r.Name] = r.Elo
+	}
+	for i, r := range t.Leaderboard {
+		if elo, ok := before[r.Name]; ok {
+			t.Leaderboard[i].Before = elo
+		}
+	}
+}
+
+func (t Tournament) String() string {
+	output := fmt.Sprintf("%d entrants, %d games\n", len(t.Entrants), len(t.Matches))
+	output += fmt.Sprintf("%4s %-24s %6s %15s %6s %7s\n", "rank", "entrant", "elo", "95% interval", "games", "score")
+	for rank, r := range t.Leaderboard {
+		score := "      -" // a seat with no games has no score
+		if r.Games > 0 {
+			score = fmt.Sprintf("%6.1f%%", 100*r.Score/float64(r.Games))
+		}
+		output += fmt.Sprintf("%4d %-24s %6.0f   [%5.0f,%5.0f] %6d %s", rank+1, r.Name, r.Elo, r.Low, r.High, r.Games, score)
+		if r.Before != 0 {
+			output += fmt.Sprintf("  (was %.0f)", r.Before)
+		}
+		output += "\n"
+	}
+	return output
+}
@@ -0,0 +1,163 @@
+/*
+Package solver searches the rest of a game exactly, when both players know everything: the order of the
+stock and what's in each other's hand.  Near the end of a game, with a few cards left in the stock and
+small hands, the tree is small enough to search in full.
+
+Every decision is put to both players through the engine's Choosers, by way of a game.Searcher, so the
+solver plays by exactly the rules the engine does.  Seat 0 plays for the
+largest VP margin and seat 1 for the smallest, with alpha-beta pruning.  A game.Table can be given to
+remember positions, since the same one is often reached by playing cards in a different order, even
+within a turn.
+*/
+package solver
+
+import (
+	"fmt"
+
+	"github.com/chrislunt/warwick/game"
+)
+
+// A Step is one decision along a line of play
+type Step struct {
+	Turn     int
+	Seat     int
+	Decision int
+	Choice   string
+	Options  int // how many legal options there were
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("turn %d: player %d %s: %s", s.Turn, s.Seat, game.DecisionName(s.Decision), s.Choice)
+}
+
+// Result is the value of a position with best play from both sides
+type Result struct {
+	Margin int    // seat 0's VP less seat 1's at the end of the game
+	VP     []int  // the final score
+	Line   []Step // the decisions of the best line
+	Nodes  int    // how many decisions were searched
+	Exact  bool   // false if the search ran out of nodes, and some lines were judged by the VP on the table
+	Hits   int    // how many positions were found in the table and not searched again
+}
+
+func (r Result) String() string {
+	output := ""
+	for _, step := range r.Line {
+		output += fmt.Sprintln(step)
+	}
+	exact := "exact"
+	if !r.Exact {
+		exact = "hit the node limit, not exact"
+	}
+	output += fmt.Sprintf("Best play ends %d - %d, a margin of %+d for player 0 (%d decisions searched, %d found in the table, %s)\n",
+		r.VP[0], r.VP[1], r.Margin, r.Nodes, r.Hits, exact)
+	return output
+}
+
+type search struct {
+	*game.Searcher
+	table *game.Table
+	root  int // how deep the search started, where the table isn't looked at
+	limit int
+	nodes int
+	hits  int
+	exact bool
+}
+
+func (s *search) margin() int {
+	vp := s.VictoryPoints()
+	return vp[0] - vp[1]
+}
+
+// value searches below the decision waiting, giving the margin with best play and the line that gets it
+func (s *search) value(alpha, beta int) (best int, line []game.Choice) {
+	next := s.Decision()
+	if next == nil {
+		return s.margin(), append([]game.Choice(nil), s.Line()...)
+	}
+	if s.nodes >= s.limit {
+		// out of time, so judge the position by the table as it stands
+		s.exact = false
+		return s.margin(), append([]game.Choice(nil), s.Line()...)
+	}
+	stored := s.table != nil
+	if stored && len(s.Line()) > s.root {
+		if e, ok := s.table.Get(s.Hash()); ok {
+			if e.Bound == game.Exact || (e.Bound == game.LowerBound && e.Value >= beta) || (e.Bound == game.UpperBound && e.Value <= alpha) {
+				// the line stops here, and Solve picks it up again
+				s.hits++
+				return e.Value, append([]game.Choice(nil), s.Line()...)
+			}
+		}
+	}
+	s.nodes++
+	// alpha and beta close in as options are searched, so keep the window this position was searched in
+	from, window, ceiling := s.nodes, alpha, beta
+	maximize := next.Seat == 0
+	option := -1
+	for i := range next.Options {
+		s.Apply(i)
+		v, l := s.value(alpha, beta)
+		s.Undo()
+		if line == nil || (maximize && v > best) || (!maximize && v < best) {
+			best, line, option = v, l, i
+		}
+		if maximize && best > alpha {
+			alpha = best
+		} else if !maximize && best < beta {
+			beta = best
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	// a value judged at the node limit isn't worth keeping
+	if stored && s.exact {
+		bound := game.Exact
+		if best <= window {
+			bound = game.UpperBound
+		} else if best >= ceiling {
+			bound = game.LowerBound
+		}
+		s.table.Put(game.Entry{Hash: s.Hash(), Value: best, Bound: bound, Depth: s.nodes - from, Best: option})
+	}
+	return
+}
+
+/*
+Solve finds the best play from the start of the current turn to the end of the game.  The game itself isn't
+changed.  limit caps how many decisions are searched, since the tree grows quickly with the stock; past it
+the result is only an estimate.  table can be nil, or shared with other searches of the same game.
+*/
+func Solve(g *game.Game, seats []game.Seat, limit int, table *game.Table) Result {
+	s := &search{Searcher: game.NewSearcher(g, seats), table: table, limit: limit, exact: true}
+	best, line := s.value(-1000, 1000)
+	result := Result{Margin: best, Exact: s.exact}
+	for {
+		for _, choice := range line[len(s.Line()):] {
+			s.Apply(choice.Option)
+			result.Line = append(result.Line, Step{Turn: choice.Turn, Seat: choice.Decision.Seat, Decision: choice.Decision.Type,
+				Choice: choice.String(), Options: len(choice.Decision.Options)})
+		}
+		if s.Decision() == nil || s.nodes >= s.limit {
+			break
+		}
+		// the line stopped at a position found in the table, so search on from there for the rest of it
+		s.root = len(s.Line())
+		_, line = s.value(-1000, 1000)
+	}
+	result.VP = s.VictoryPoints()
+	result.Nodes, result.Hits = s.nodes, s.hits
+	return result
+}
+
+// PlayOut plays the rest of the game with the players making their own decisions, for comparing against
+// best play.  The game itself isn't changed.
+func PlayOut(g *game.Game, seats []game.Seat) (vp []int) {
+	end, err := game.Restore(g.State(), seats)
+	if err != nil {
+		panic(err)
+	}
+	end.Play()
+	return end.VictoryPoints()
+}
@@ -0,0 +1,34 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/chrislunt/warwick/game"
+	"github.com/chrislunt/warwick/player"
+)
+
+// TestTable solves the end of some games with and without a table, which has to give the same margins,
+// and the line found with the table has to come out at that margin
+func TestTable(t *testing.T) {
+	game.LogLevel, player.LogLevel = 0, 0
+	for seed := int64(1); seed <= 10; seed++ {
+		g := game.New(game.Options{Seed: seed})
+		for !g.Over && g.Stock.PullPos+1 > 3 {
+			g.PlayTurn()
+		}
+		if g.Over {
+			continue
+		}
+		plain := Solve(g, nil, 1000000, nil)
+		tabled := Solve(g, nil, 1000000, game.NewTable(1<<16))
+		if !plain.Exact || !tabled.Exact {
+			t.Fatalf("seed %d: the search hit the node limit", seed)
+		}
+		if plain.Margin != tabled.Margin {
+			t.Errorf("seed %d: a margin of %+d without the table, %+d with it", seed, plain.Margin, tabled.Margin)
+		}
+		if margin := tabled.VP[0] - tabled.VP[1]; margin != tabled.Margin {
+			t.Errorf("seed %d: the best line ends with a margin of %+d, the search gave %+d", seed, margin, tabled.Margin)
+		}
+	}
+}
@@ -67,310 +67,542 @@ Each player gets 5 cards.
 package main
 
 import (
+	"flag"
 	"fmt"
-	"math/rand"
+	"os"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
-	"github.com/chrislunt/warwick/card"
+
+	"github.com/chrislunt/warwick/bot"
+	"github.com/chrislunt/warwick/game"
 	"github.com/chrislunt/warwick/player"
+	"github.com/chrislunt/warwick/remote"
+	"github.com/chrislunt/warwick/review"
+	"github.com/chrislunt/warwick/scenarios"
+	"github.com/chrislunt/warwick/sim"
+	"github.com/chrislunt/warwick/solver"
 )
 
-var logLevel = 2
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "play":
+			check(play(os.Args[2:]))
+			return
+		case "balance":
+			balance(os.Args[2:])
+			return
+		case "tournament":
+			check(tournament(os.Args[2:]))
+			return
+		case "compare":
+			compare(os.Args[2:])
+			return
+		case "solve":
+			solve(os.Args[2:])
+			return
+		case "fuzz":
+			fuzz(os.Args[2:])
+			return
+		case "scenarios":
+			listScenarios(os.Args[2:])
+			return
+		case "serve":
+			check(serve(os.Args[2:]))
+			return
+		case "join":
+			check(join(os.Args[2:]))
+			return
+		case "host":
+			host(os.Args[2:])
+			return
+		}
+	}
+	check(play(os.Args[1:]))
+}
+
+
+// fail stops with the error, without running any deferred calls, so it's only for commands that defer nothing
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
 
-func log(level int, message string) {
-	if logLevel >= level { 
-		fmt.Println(message);
+// check stops with the error a command gave back, if it gave one, once the command's deferred calls have run
+func check(err error) {
+	if err != nil {
+		fail(err)
 	}
 }
 
 
-func turnToPhase(turn int) (phase int) {
-	if turn > 6 {
-		phase = 2
-	} else if turn > 3 {
-		phase = 1 
-	} else {
-		phase = 0
+// the bots started for this run, to close at the end
+var bots []*bot.Agent
+
+// parseSeat is game.ParseSeat, and also starts bot:command as a bot program, see package bot
+func parseSeat(spec string, timeout time.Duration) (game.Seat, error) {
+	command, isBot := strings.CutPrefix(spec, "bot:")
+	if !isBot {
+		return game.ParseSeat(spec)
 	}
-	return
+	agent, err := bot.Start(command, timeout)
+	if err != nil {
+		return game.Seat{}, err
+	}
+	bots = append(bots, agent)
+	return game.Seat{Name: agent.Name, Chooser: agent}, nil
 }
 
+func closeBots() {
+	for _, agent := range bots {
+		agent.Close()
+	}
+	bots = nil
+}
+
+
+func play(args []string) error {
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	seat0 := flags.String("seat0", "human", "who plays first: human, heuristic, warlord, builder, turtle or merchant, optionally with :strategy.json, or bot:command")
+	seat1 := flags.String("seat1", "heuristic", "who plays second, human too for two people taking turns at the one terminal")
+	botTime := flags.Duration("bottime", bot.DefaultTimeout, "how long a bot has to make each decision")
+	save := flags.String("save", "", "write the game to this file at the start of every turn, to pick up with solve -load")
+	analysis := flags.Bool("analysis", false, "show the computer's view of your options at every decision (type h for a hint any time)")
+	reviewGame := flags.Bool("review", false, "after the game, find the human players' costliest decisions")
+	rollouts := flags.Int("rollouts", 16, "how many times the review plays out each option")
+	scenario := flags.String("scenario", "", "set the game up from a scenario, by name or file (see the scenarios command)")
+	plain := flags.Bool("plain", false, "draw the table without colors or clearing the screen, for a terminal that doesn't take ANSI codes")
+	flags.Parse(args)
+
+	options := game.Options{Seed: time.Now().UTC().UnixNano(), Analysis: *analysis}
+	if *scenario != "" {
+		var err error
+		if options.Scenario, err = scenarios.Load(*scenario); err != nil {
+			return err
+		}
+	}
+	defer closeBots()
+	// two humans take turns at the one terminal
+	console := player.NewConsole(os.Stdin, os.Stdout)
+	console.Plain = *plain
+	for _, spec := range []string{*seat0, *seat1} {
+		seat, err := parseSeat(spec, *botTime)
+		if err != nil {
+			return err
+		}
+		if seat.Human {
+			seat.UI = console
+		}
+		options.Seats = append(options.Seats, seat)
+		// a human's decisions are kept so they can be reviewed
+		options.Record = options.Record || (seat.Human && *reviewGame)
+	}
+	g := game.New(options)
+	for !g.Over {
+		if *save != "" {
+			if err := g.Save(*save); err != nil {
+				return err
+			}
+		}
+		g.PlayTurn()
+	}
+
+	// determine the winner
+	if game.LogLevel > 0 {
+		vp := g.VictoryPoints()
+		for id, currentPlayer := range g.Players {
+			fmt.Println("Player", id, "Tableau:  ", currentPlayer.Tableau)
+		}
+
+		fmt.Println("Player 0", vp[0], "-", vp[1], "Player 1")
+		if g.Forfeited != -1 {
+			fmt.Println("Player", g.Forfeited, "forfeited")
+		}
+		switch g.Winner() {
+		case -1:
+			fmt.Println("Tie game")
+		case 1:
+			fmt.Println("Player 1 wins!")
+		default:
+			fmt.Println("Player 0 wins!")
+		}
+	}
 
-func store(storePower int, stock *card.Hand, discardPile *card.Hand, player *player.Player, phase int) {
-	var topSpot int
-	switch {
-	case storePower == 1 || storePower == 2:
-		// the player may choose from hand, discard or stock to fill the storage
-		// if the spot is open, you may refill it
-		topSpot = 0
-	case storePower == 3 || storePower == 4:
-		// a second storage spot opens, fill from stock, discard or hand
-		// for a 4, refill either open storage spots
-		topSpot = 1
-	}
-	for spot := 0; spot <= topSpot; spot++ {
-		if (*player).Tableau.Storage[spot] == nil {
-			storeCard := (*player).ChooseStore(stock, discardPile, phase)
-			log(1, fmt.Sprintf("Stored in storage %d: %s", spot, storeCard))
-			(*player).Tableau.Storage[spot] = storeCard
+	if !options.Record {
+		return nil
+	}
+	fmt.Println("Reviewing your game...")
+	level := game.LogLevel
+	quiet()
+	for id, seat := range options.Seats {
+		if !seat.Human {
+			continue
 		}
+		r, err := review.Game(g, id, *rollouts, options.Seed)
+		if err != nil {
+			return err
+		}
+		fmt.Print(r)
 	}
+	game.LogLevel, player.LogLevel = level, level
+	return nil
 }
 
 
-func buildStock() (stock card.Hand, stockSize int) {
-	rand.Seed( time.Now().UTC().UnixNano() )
-
-	// double the deck.  This is the canonical reference of all cards.
-	var	allCards = append(card.Deck[:], card.Deck[:]...)
-	stockSize = len(allCards)
-
-	// the stock, which can shrink, is a reference to all cards
-	stock.Cards = make([]*card.Card, stockSize)
-	stock.PullPos = stockSize - 1 // the position representing the current position to draw from
-
-	/* There are two ways we could randomize, one would be randomize the stock and keep a pointer of where we currently are,
-		which has an up-front randomization cost, but all subsequent pulls are cheap.  
-	*/
-	// TODO make this a parameter
-	testStockId := -1
-	var permutation []int
-	if testStockId != -1 {
-		/* rather than having to specify the whole deck, I allow you to only specify the top of the deck */
-		fillSize := stockSize - len(card.TestStock[testStockId])
-		fillOut := rand.Perm(fillSize)
-		// for easier reading I specify the TestStock in reverse order, so get it ready to go on top
-		s := card.TestStock[testStockId]
-		for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
-	        s[i], s[j] = s[j], s[i]
-    	}
-		permutation = append(fillOut[0:fillSize], card.TestStock[testStockId]...);
-	} else {
-		permutation = rand.Perm(stockSize)
+// simulations are run in silence
+func quiet() {
+	game.LogLevel = 0
+	player.LogLevel = 0
+}
+
+
+// balance simulates computer games and reports how each card in the deck performed
+func balance(args []string) {
+	flags := flag.NewFlagSet("balance", flag.ExitOnError)
+	games := flags.Int("games", 1000, "number of games to simulate")
+	seed := flags.Int64("seed", 1, "seed for the first game, later games count up from it")
+	csvFile := flags.String("csv", "", "write the report as CSV to this file")
+	flags.Parse(args)
+
+	quiet()
+	report := sim.NewBalance()
+	sim.Run(*games, *seed, nil, report.Add)
+
+	if *csvFile == "" {
+		fmt.Print(report)
+		return
+	}
+	out, err := os.Create(*csvFile)
+	if err != nil {
+		fail(err)
 	}
-	for i, v := range permutation {
-		stock.Cards[i] = &allCards[v]
+	err = report.WriteCSV(out)
+	// fail exits without running deferred calls, and a failed close can mean the report never got written
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fail(err)
 	}
-	return
 }
 
 
-func main() {
-	stock, stockSize := buildStock()
-
-	var discardPile card.Hand
-	discardPile.Cards = make([]*card.Card, stockSize)
-	discardPile.PullPos = -1
-
-	var trash card.Hand
-	trash.Cards = make([]*card.Card, stockSize)
-	// trash is never pulled from, so no pull position
-
-	players := make([]player.Player, 2);
-
-	// set up rules about where you can get cards from for different actions
-	legalBuildFrom := map[int] bool{
-		player.FromHand: 	true,
-		player.FromStorage: true,
-		player.FromStock: 	false,
-		player.FromDiscard: false,
-	}
-
-	// initialize the players
-	for id := range players {
-		players[id].Hand = &card.Hand{}
-		players[id].Hand.Limit = 5
-		players[id].Hand.Max = 7
-		// create the hand with an extra 2 slots beyond the limit, which could happen
-		// if you use a soldier and then do an exchange
-		players[id].Hand.Cards = make([]*card.Card, players[id].Hand.Max)
-		// do the initial draw of 5 cards
-		stock.RandomPull(5, players[id].Hand)
-		// initize the Tableaus.  The Tableau is a map indexed by a card type constant
-		// the map points to a small hand which is the potential stack of cards as someone upgrades
-		// there are 10 types of cards, plus 2 storage spots so each slot must be initialized
-		players[id].Tableau = &card.Tableau{}
-		players[id].Tableau.Stack = make(map[int] *card.Hand)
-		players[id].Tableau.Discounts = make([]int, 4)
-		players[id].Tableau.BuildBonus = 0
-		players[id].Tableau.AttackBonus = 0
-		players[id].Tableau.Storage = make([] *card.Card, 2)
-		players[id].Human = false
-		players[id].State = "Turn 1:\n"
-
-		// the player strategy should be loaded from somewhere.  For now, set it all to 32
-		// instead of 1 value per turn, do 3 columns for beginning, middle and end.
-		// Value can be set by cost to start with.  Value may be adjusted by changes in cost.
-		// value could be affected at time of spend by what may be discarded as well.
-		players[id].Strategy = make([][][]int, 3)
-		for phase := 0; phase <= 2; phase++ {
-			players[id].Strategy[phase] = make([][]int, 10)
-			for kind := 0; kind <= 9; kind++ {
-				players[id].Strategy[phase][kind] = make([]int, 5)
-				for cost := 1; cost <= 4; cost++ {
-					players[id].Strategy[phase][kind][cost] = cost * 16 - 1
-				}
-			}
+// tournament plays every agent against every other in both seat orders and rates them
+func tournament(args []string) error {
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	games := flags.Int("games", 100, "games per pairing and seat order")
+	seed := flags.Int64("seed", 1, "seed for the first game, later games count up from it")
+	out := flags.String("out", "tournament.json", "write the full results to this file")
+	botTime := flags.Duration("bottime", bot.DefaultTimeout, "how long a bot has to make each decision")
+	previous := flags.String("previous", "", "an earlier results file to compare ratings against")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: warwick tournament [flags] agent agent...")
+		fmt.Fprintln(os.Stderr, "  an agent is heuristic, warlord, builder, turtle or merchant, optionally with :strategy.json, or just strategy.json")
+		fmt.Fprintln(os.Stderr, "  or bot:command for a program that speaks the bot protocol, like \"bot:python3 bots/randombot.py\"")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	defer closeBots()
+	var seats []game.Seat
+	for _, spec := range flags.Args() {
+		seat, err := parseSeat(spec, *botTime)
+		if err != nil {
+			return err
+		}
+		if seat.Human {
+			return fmt.Errorf("a human can't play a tournament")
 		}
+		seats = append(seats, seat)
 	}
-	// TODO: this should be an input parameter
-	players[0].Human = true
-
-	turnLimit := 0 // you can use this to cut a game short for dev purposes
-	turnCount := 0
-	gameOver := false
-	// play until the deck runs out
-	// or until the first player fills everything in their table (soldier doesn't matter)
-	for (stock.PullPos > -1) && ((turnLimit == 0) || (turnCount < turnLimit)) && !gameOver {
-		turnCount++
-		phase := turnToPhase(turnCount)
-
-		// for safety
-		// if you can't build any of the cards in your hand (because those positions are filled), you can get stuck
-		if turnCount > 29 {
-			fmt.Println("The game went to 30 turns--ending as a safety")
-			gameOver = true
+
+	quiet()
+	t := sim.RunTournament(seats, *games, *seed)
+	if *previous != "" {
+		earlier, err := sim.LoadTournament(*previous)
+		if err != nil {
+			return err
+		}
+		t.CompareTo(earlier)
+	}
+	fmt.Print(t)
+	if *out != "" {
+		if err := t.Save(*out); err != nil {
+			return err
 		}
+		fmt.Println("Results written to", *out)
+	}
+	return nil
+}
 
-		// turns
-		var opponent player.Player
-		for id, currentPlayer := range players {
-			if id == 0 {
-				opponent = players[1]
-			} else {
-				opponent = players[0]
-			}
-			// we keep track of messages to send to the Human player
-			if opponent.Human {
-				players[0].State = fmt.Sprintf("Turn: %d\n", turnCount + 2)
-			}
 
-			// if we're coming back to this player and they already have 9 cards, it's time to stop
-			if currentPlayer.Tableau.Fill == 9 {
-				gameOver = true
-				break;
-				// there is an error here in that if player 1 goes out first, player 0 doesn't get another play
-			}
-			// turn order:
-			// 1. Build 
-			// 2. Attack
-			// 3. Trash (with Market)
-			// 4. Draw up to 5 OR discard down to 5
-
-			// determine card to build, cost
-			// determine discards
-			// do build
-//			log(2, fmt.Sprintf("Player %d hand: %s", id, currentPlayer.Hand))
-//			log(2, fmt.Sprintf("Player %d Tableau: %s", id, currentPlayer.Tableau))
-
-			builds := 0
-
-			// we check it each time, since if you build the card, you get to use it immediately
-			for builds < (currentPlayer.Tableau.BuildBonus + 1) {
-				buildPos, cost, upgrade := currentPlayer.PlayerChooses(legalBuildFrom, phase)
-			    var discards []player.Pos
-			    if buildPos.From != player.NoCard {
-			    	log(1, fmt.Sprintf("Player %d builds %s for %d", id, currentPlayer.CardByPos(buildPos), cost))
-				    if cost > 0 {
-			    		discards = currentPlayer.ChooseDiscards(buildPos, cost, phase)
-			    		if logLevel > 1 {
-							fmt.Println("Player", id, "discards:")
-							for _, pos := range discards {
-								fmt.Println(currentPlayer.CardByPos(pos))
-							}	
-						}
-					}
-					kind := currentPlayer.CardByPos(buildPos).Kind
-				    cardValue := currentPlayer.CardByPos(buildPos).Cost
-					currentPlayer.Build(buildPos, discards, &discardPile, upgrade)
-					// if it's storage, you get a chance to place a card
-					if kind == card.Storage {
-						store(cardValue, &stock, &discardPile, &currentPlayer, phase);
-					}
-
-					log(2, fmt.Sprintf("currentPlayer %d has %d cards left", id, currentPlayer.Hand.Count))
-					builds++
-
-			    } else {
-			    	break;
-			    }
-			}
+// compare plays the same deals under two readings of the rules and reports what changed
+func compare(args []string) {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	games := flags.Int("games", 1000, "number of deals to play under each set of rules")
+	seed := flags.Int64("seed", 1, "seed for the first deal, later deals count up from it")
+	a := flags.String("a", "", "rules A as name=value,... (empty for the default rules)")
+	b := flags.String("b", "", "rules B as name=value,...")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: warwick compare [flags]")
+		fmt.Fprintln(os.Stderr, "  rules: storage=refill|cardtext spendstorage=true|false handlimit=trash|discard")
+		fmt.Fprintln(os.Stderr, "         soldiers=trash|discard redraw=true|false turncap=30 defend=true|false")
+		fmt.Fprintln(os.Stderr, "         redrawtrash=true|false")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *games < 1 {
+		fail(fmt.Errorf("compare needs at least one game, not %d", *games))
+	}
 
-	    	// When they don't build, and they have cards, check if they'd like to trash and redraw
-			if builds == 0 {
-		    	preResetCount := currentPlayer.Hand.Count 
-		    	if (currentPlayer.Human && preResetCount > 0 && currentPlayer.HumanWantsRedraw()) || (currentPlayer.Hand.Count == currentPlayer.Hand.Limit) {
-			   		// if the computer player can't build, but they have a full hand, they will get stuck.  Invoke the hand reset rule
-			    	currentPlayer.Hand.Reset()
-    				stock.RandomPull(preResetCount, players[id].Hand)
-				   	fmt.Println("Player", id, "dumps their hand and redraws") 
-				   	// if you recycle your hand, you don't get to do any builds, attacks, exchanges
-    				continue;
-				}
-			}
+	rulesA, err := game.ParseRules(*a)
+	if err != nil {
+		fail(err)
+	}
+	rulesB, err := game.ParseRules(*b)
+	if err != nil {
+		fail(err)
+	}
+	quiet()
+	fmt.Print(sim.CompareRules(rulesA, rulesB, *games, *seed))
+}
+
+
+// solve searches the end of a game exactly, and sets it against how the players would have played it
+func solve(args []string) {
+	flags := flag.NewFlagSet("solve", flag.ExitOnError)
+	load := flags.String("load", "", "a game saved with play -save to solve from")
+	seed := flags.Int64("seed", 1, "without -load, deal a game with this seed")
+	scenario := flags.String("scenario", "", "without -load, set the game up from a scenario, by name or file")
+	stock := flags.Int("stock", 6, "without -load, let the players play until this many cards are left in the stock")
+	nodes := flags.Int("nodes", 1000000, "the most decisions to search")
+	table := flags.Int("table", 1<<20, "how many positions to remember, 0 to search without a table")
+	seat0 := flags.String("seat0", "heuristic", "who plays first, to compare against best play")
+	seat1 := flags.String("seat1", "heuristic", "who plays second")
+	flags.Parse(args)
+
+	var seats []game.Seat
+	for _, spec := range []string{*seat0, *seat1} {
+		seat, err := game.ParseSeat(spec)
+		if err != nil {
+			fail(err)
+		}
+		if seat.Human {
+			fail(fmt.Errorf("a human can't be compared against the solver"))
+		}
+		seats = append(seats, seat)
+	}
+
+	var setup *game.Scenario
+	if *scenario != "" {
+		var err error
+		if setup, err = scenarios.Load(*scenario); err != nil {
+			fail(err)
+		}
+	}
+
+	quiet()
+	var g *game.Game
+	if *load != "" {
+		var err error
+		if g, err = game.Load(*load, seats); err != nil {
+			fail(err)
+		}
+	} else {
+		g = game.New(game.Options{Seed: *seed, Seats: seats, Scenario: setup})
+		for !g.Over && g.Stock.PullPos+1 > *stock {
+			g.PlayTurn()
+		}
+	}
+	if g.Over {
+		fail(fmt.Errorf("the game is already over, on turn %d with %d cards in the stock", g.Turn, g.Stock.PullPos+1))
+	}
+	vp := g.VictoryPoints()
+	fmt.Printf("Turn %d, player %d to play, %d cards in the stock, %d - %d on the table\n",
+		g.Turn, g.ToMove, g.Stock.PullPos+1, vp[0], vp[1])
+	for id, p := range g.Players {
+		fmt.Printf("Player %d hand: %s\nPlayer %d tableau:\n%s", id, p.Hand, id, p.Tableau)
+	}
+
+	var positions *game.Table
+	if *table > 0 {
+		positions = game.NewTable(*table)
+	}
+	result := solver.Solve(g, seats, *nodes, positions)
+	fmt.Print(result)
+	played := solver.PlayOut(g, seats)
+	fmt.Printf("%s vs %s play it out %d - %d, a margin of %+d\n", seats[0].Name, seats[1].Name, played[0], played[1], played[0]-played[1])
+}
 
-			// ------ Attack --------- //
-			steal := currentPlayer.ChooseAttack(opponent, phase) // steal is a card kind
-			if steal != -1 {
-//				log(1, fmt.Sprintf("Player %d uses %s and takes opponent's %s", id, currentPlayer.TopCard(card.Soldiers), opponent.TopCard(steal)))
-				if opponent.Human{
-					players[0].State += fmt.Sprintf("ALERT: Opponent used a %s to take your %s\n", currentPlayer.TopCard(card.Soldiers), opponent.TopCard(steal))
-				}
-				opponent.Tableau.RemoveTop(steal, currentPlayer.Hand) 
-				// then loose your attack card
-				currentPlayer.Tableau.RemoveTop(card.Soldiers, &trash) // TODO: remove to trash, test if it works
-			}
 
-			// ------- TRASH --------- //
-			cardsTrashed := 0
-			// TrashBonus measures the amount of cards you can trash in order to draw a new one
-			if currentPlayer.Tableau.TrashBonus > 0 && currentPlayer.Hand.Count > 0 {
-				trashPoses := currentPlayer.ChooseTrash(phase)
-				cardsTrashed = currentPlayer.TrashCards(trashPoses, &trash)
+// listScenarios shows the scenarios that come with the game, or runs their tests
+func listScenarios(args []string) {
+	flags := flag.NewFlagSet("scenarios", flag.ExitOnError)
+	test := flags.Bool("test", false, "play each scenario's script and check it comes out as expected, or just the scenarios named after the flags")
+	flags.Parse(args)
+
+	if *test {
+		quiet()
+		var results scenarios.Results
+		if flags.NArg() == 0 {
+			var err error
+			if results, err = scenarios.RunTests(); err != nil {
+				fail(err)
+			}
+		}
+		// or just the scenarios named, which can be files
+		for _, spec := range flags.Args() {
+			t, err := scenarios.LoadTest(spec)
+			if err != nil {
+				fail(err)
 			}
-			// you must trash card to get the draw bonus under the current rules
-			if (currentPlayer.Tableau.DrawBonus > 0) && (cardsTrashed > 0) {
-				stock.RandomPull(currentPlayer.Tableau.DrawBonus, players[id].Hand)
-			    log(1, fmt.Sprintf("Player %d bonus draws %d", id, currentPlayer.Tableau.DrawBonus))
+			if t == nil {
+				fail(fmt.Errorf("%s has nothing to expect", spec))
 			}
-
-			// ------- DRAW --------- //
-			// see how many open spots there are in the hand.  This may not run at all
-			currentPlayer.Draw(&discardPile, &stock, phase)
-
-		    // ------- DISCARD --------- //
-		    // TODO: allow player to choose discard
-		    for currentPlayer.Hand.Count > currentPlayer.Hand.Limit {
-		    	fmt.Println("=================== Player", id, "has", currentPlayer.Hand.Count, "cards =================");
-		    	trashPos, _ := currentPlayer.LowestValueCard(phase, nil)
-		    	currentPlayer.Hand.RemoveCard(trashPos.Index, &trash)
-		    }
-
-		    // if a human is playing record the state to share with them at the beginning of their turn
-		    // this assumes the human always goes first
-		    if opponent.Human {
-	    		players[0].State += fmt.Sprintf("Opponent Tableau:\n%s\n", currentPlayer.Tableau)
-		    	players[0].State += fmt.Sprintf("Your Tableau:\n%s\n", opponent.Tableau)
-		    }
+			results = append(results, scenarios.Result{Name: t.Scenario.Name, Failures: t.Run()})
 		}
-	    fmt.Println("----END OF TURN----")
+		fmt.Print(results)
+		if results.Failed() > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	list, err := scenarios.All()
+	if err != nil {
+		fail(err)
+	}
+	for _, s := range list {
+		fmt.Printf("%-20s %s\n", s.Name, s.Description)
 	}
+}
 
-	// determine the winner
-	if logLevel > 0 {
-		vp := make([]int, 2)
-		for id, currentPlayer := range players {
-			 fmt.Println("Player", id, "Tableau:  ", currentPlayer.Tableau) 
-			 vp[id] = currentPlayer.VictoryPoints()
+
+// fuzz plays random games looking for a broken rule in the engine
+func fuzz(args []string) {
+	flags := flag.NewFlagSet("fuzz", flag.ExitOnError)
+	games := flags.Int("games", 1000, "number of games to play")
+	seed := flags.Int64("seed", 1, "seed for the random games")
+	length := flags.Int("length", 400, "how many random decisions each game gets before it takes the first option")
+	save := flags.String("save", "", "write each shrunk failure as a scenario test in this directory")
+	flags.Parse(args)
+
+	quiet()
+	report := sim.Fuzz(*games, *seed, *length)
+	fmt.Print(report)
+	if *save != "" {
+		if err := os.MkdirAll(*save, 0755); err != nil {
+			fail(err)
+		}
+		for _, f := range report.Failures {
+			name := fmt.Sprintf("fuzz-%s-%d", strings.ReplaceAll(f.Rule(), " ", "-"), f.Seed)
+			path := filepath.Join(*save, name+".json")
+			if err := f.Test(name).Save(path); err != nil {
+				fail(err)
+			}
+			fmt.Printf("Saved %s\n", path)
 		}
+	}
+	if len(report.Failures) > 0 {
+		os.Exit(1)
+	}
+}
 
-		fmt.Println("Player 0", vp[0], "-", vp[1], "Player 1")
-		if vp[0] == vp[1] {
-			fmt.Println("Tie game")
-		} else if vp[0] < vp[1] {
-			fmt.Println("Player 1 wins!")
-		} else {
-			fmt.Println("Player 0 wins!")				
+
+// serve hosts a game over TCP for players to join from other machines, see package remote
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":7070", "the address to listen on")
+	seat0 := flags.String("seat0", "remote", "who plays first: remote for a player who joins, or a computer player as for play")
+	seat1 := flags.String("seat1", "remote", "who plays second")
+	botTime := flags.Duration("bottime", bot.DefaultTimeout, "how long a bot has to make each decision")
+	idle := flags.Duration("idle", 0, "how long a remote player has to answer before they forfeit, 0 to wait for ever")
+	scenario := flags.String("scenario", "", "set the game up from a scenario, by name or file (see the scenarios command)")
+	seed := flags.Int64("seed", 0, "seed for the deal, 0 for a new one every game")
+	flags.Parse(args)
+
+	options := game.Options{Seed: *seed}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UTC().UnixNano()
+	}
+	if *scenario != "" {
+		var err error
+		if options.Scenario, err = scenarios.Load(*scenario); err != nil {
+			return err
 		}
 	}
+	defer closeBots()
+	var remotes [2]bool
+	for id, spec := range []string{*seat0, *seat1} {
+		if spec == "remote" {
+			remotes[id] = true
+			options.Seats = append(options.Seats, game.Seat{})
+			continue
+		}
+		seat, err := parseSeat(spec, *botTime)
+		if err != nil {
+			return err
+		}
+		if seat.Human {
+			return fmt.Errorf("a human plays a served game by joining it, make the seat remote")
+		}
+		options.Seats = append(options.Seats, seat)
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	quiet()
+	table := remote.NewTable(options, remotes)
+	table.Idle = *idle
+	go table.Play()
+	fmt.Printf("Serving on %s, join with: warwick join -addr host%s\n", l.Addr(), (*addr)[strings.LastIndex(*addr, ":"):])
+	if err := remote.Serve(l, table); err != nil {
+		return err
+	}
+	g := table.Game()
+	vp := g.VictoryPoints()
+	fmt.Println("Player 0", vp[0], "-", vp[1], "Player 1")
+	return nil
+}
+
 
-}
\ No newline at end of file
+// join plays a seat of a game hosted with serve
+func join(args []string) error {
+	flags := flag.NewFlagSet("join", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:7070", "the server's address")
+	name := flags.String("name", os.Getenv("USER"), "your name, for the other player to see")
+	seat := flags.Int("seat", -1, "the seat to take, 0 to play first or 1 second, -1 for the first one free")
+	token := flags.String("token", "", "the token the server gave, to take your seat back")
+	plain := flags.Bool("plain", false, "draw the table without colors or clearing the screen, for a terminal that doesn't take ANSI codes")
+	flags.Parse(args)
+
+	console := player.NewConsole(os.Stdin, os.Stdout)
+	console.Plain = *plain
+	request := remote.Request{Name: *name, Token: *token}
+	if *seat != -1 {
+		request.Seat = seat
+	}
+	return remote.Join(*addr, request, console)
+}
+
+
+// host serves games over HTTP, for front ends that aren't a terminal and for playing in a browser, see remote.Host
+func host(args []string) {
+	flags := flag.NewFlagSet("host", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "the address to listen on")
+	flags.Parse(args)
+
+	quiet()
+	fmt.Printf("Hosting games on %s, play in a browser at http://localhost%s/ or create one with POST /games\n", *addr, (*addr)[strings.LastIndex(*addr, ":"):])
+	if err := http.ListenAndServe(*addr, remote.NewHost()); err != nil {
+		fail(err)
+	}
+}